@@ -0,0 +1,103 @@
+package cmd
+
+import (
+	"fmt"
+	"manga-cli/internals/daemon"
+	"os"
+
+	"github.com/spf13/cobra"
+)
+
+var daemonCmd = &cobra.Command{
+	Use:   "daemon",
+	Short: "Process the download queue in the background",
+	Long: `Runs the download queue and listens on a control socket in ~/.manga-cli.
+While the daemon is running, download and queue commands hand their work to it
+instead of downloading in the current terminal.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		detach, _ := cmd.Flags().GetBool("detach")
+
+		if detach {
+			if daemon.Running() {
+				fmt.Println("Daemon is already running.")
+				return
+			}
+			var daemonArgs []string
+			if rate, _ := cmd.Flags().GetString("limit-rate"); rate != "" {
+				daemonArgs = append(daemonArgs, "--limit-rate", rate)
+			}
+			pid, err := daemon.Detach(daemonArgs...)
+			if err != nil {
+				fmt.Println("Failed to start daemon:", err)
+				os.Exit(1)
+			}
+			logPath, _ := daemon.LogPath()
+			fmt.Printf("Daemon started (pid %d), logging to %s\n", pid, logPath)
+			return
+		}
+
+		if err := applyRateLimit(cmd); err != nil {
+			fmt.Println("Error:", err)
+			os.Exit(1)
+		}
+
+		ctx, stop := interruptContext(cmd.Context())
+		defer stop()
+
+		if err := daemon.Serve(ctx); err != nil {
+			fmt.Println("Daemon error:", err)
+			os.Exit(1)
+		}
+	},
+}
+
+var daemonStopCmd = &cobra.Command{
+	Use:   "stop",
+	Short: "Stop the background daemon, re-queueing the chapter in progress",
+	Run: func(cmd *cobra.Command, args []string) {
+		if !daemon.Running() {
+			fmt.Println("Daemon is not running.")
+			return
+		}
+		if _, err := daemon.Call(daemon.Request{Action: daemon.ActionStop}); err != nil {
+			fmt.Println("Error:", err)
+			os.Exit(1)
+		}
+		fmt.Println("Daemon stopping.")
+	},
+}
+
+var statusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "Show the download daemon and queue status",
+	Run: func(cmd *cobra.Command, args []string) {
+		if !daemon.Running() {
+			fmt.Println("Daemon: not running")
+			printQueueSummary()
+			return
+		}
+
+		resp, err := daemon.Call(daemon.Request{Action: daemon.ActionStatus})
+		if err != nil {
+			fmt.Println("Error:", err)
+			os.Exit(1)
+		}
+
+		st := resp.Status
+		fmt.Printf("Daemon: running (pid %d, since %s)\n", st.PID, st.StartedAt.Format("2006-01-02 15:04"))
+		if st.Current != nil {
+			fmt.Printf("Downloading: %s chapter %s\n", st.Current.Title, st.Current.Chapter)
+		} else {
+			fmt.Println("Downloading: idle")
+		}
+		fmt.Printf("Queue: %d pending, %d paused, %d failed, %d done\n", st.Pending, st.Paused, st.Failed, st.Done)
+	},
+}
+
+func init() {
+	daemonCmd.Flags().Bool("detach", false, "Start the daemon in the background and return")
+	daemonCmd.Flags().String("limit-rate", "", "Maximum download speed, e.g. 500K or 2M")
+	daemonCmd.AddCommand(daemonStopCmd)
+	AddSubCommand(daemonCmd)
+	AddSubCommand(statusCmd)
+}
@@ -0,0 +1,170 @@
+package cmd
+
+import (
+	"fmt"
+	"manga-cli/internals/library"
+	"manga-cli/internals/queue"
+	"manga-cli/internals/utils"
+	"os"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+var deleteCmd = &cobra.Command{
+	Use:   "delete",
+	Short: "Delete downloaded chapters or a whole manga",
+	Run: func(cmd *cobra.Command, args []string) {
+		dTitle, _ := cmd.Flags().GetString("title")
+		chaptersSpec, _ := cmd.Flags().GetString("chapters")
+		all, _ := cmd.Flags().GetBool("all")
+		dryRun, _ := cmd.Flags().GetBool("dry-run")
+
+		dTitle = library.ResolveTitle(dTitle)
+		dir, err := library.MangaDir(dTitle)
+		if err != nil {
+			fmt.Println("Error:", err)
+			os.Exit(1)
+		}
+		if _, err := os.Stat(dir); os.IsNotExist(err) {
+			fmt.Printf("Manga title '%s' not found in downloads.\n", dTitle)
+			os.Exit(1)
+		}
+
+		if all {
+			size, _ := library.DirSize(dir)
+			if dryRun {
+				fmt.Printf("Would delete '%s' (%s)\n", dTitle, utils.FormatBytes(size))
+				return
+			}
+			freed, err := library.RemoveManga(dTitle)
+			if err != nil {
+				fmt.Println("Error:", err)
+				os.Exit(1)
+			}
+			fmt.Printf("Deleted '%s', freed %s\n", dTitle, utils.FormatBytes(freed))
+			return
+		}
+
+		if chaptersSpec == "" {
+			fmt.Println("Please specify --chapters (e.g. 1-50,52) or --all")
+			os.Exit(1)
+		}
+
+		ranges, err := utils.ParseChapterRanges(chaptersSpec)
+		if err != nil {
+			fmt.Println("Error:", err)
+			os.Exit(1)
+		}
+
+		items, err := library.ChaptersInRanges(dTitle, ranges)
+		if err != nil {
+			fmt.Println("Error:", err)
+			os.Exit(1)
+		}
+		removeItems(items, dryRun)
+	},
+}
+
+var pruneCmd = &cobra.Command{
+	Use:   "prune",
+	Short: "Remove read chapters, empty folders and partial downloads",
+	Long: `Without any of --read, --empty or --partial, all three are pruned.
+Read chapters are only removed once they were finished more than --days ago.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		read, _ := cmd.Flags().GetBool("read")
+		empty, _ := cmd.Flags().GetBool("empty")
+		partial, _ := cmd.Flags().GetBool("partial")
+		days, _ := cmd.Flags().GetInt("days")
+		dryRun, _ := cmd.Flags().GetBool("dry-run")
+
+		if !read && !empty && !partial {
+			read, empty, partial = true, true, true
+		}
+
+		unfinished := map[string]bool{}
+		running := map[string]bool{}
+		if q, err := loadQueue(); err == nil {
+			for _, j := range q.Jobs {
+				key := library.ChapterKey(j.Title, j.Chapter)
+				if j.Status == queue.StatusRunning {
+					running[key] = true
+				} else if j.Status != queue.StatusDone {
+					unfinished[key] = true
+				}
+			}
+		}
+
+		items, err := library.FindPrunable(library.PruneOptions{
+			Read:       read,
+			ReadBefore: time.Now().AddDate(0, 0, -days),
+			Empty:      empty,
+			Partial:    partial,
+			Unfinished: unfinished,
+		})
+		if err != nil {
+			fmt.Println("Error:", err)
+			os.Exit(1)
+		}
+
+		var keep []library.PruneItem
+		for _, item := range items {
+			if !running[library.ChapterKey(item.Title, item.Chapter)] {
+				keep = append(keep, item)
+			}
+		}
+		removeItems(keep, dryRun)
+	},
+}
+
+func init() {
+	deleteCmd.Flags().StringP("title", "t", "", "Manga title (required)")
+	deleteCmd.Flags().String("chapters", "", "Chapters to delete, e.g. 1-50,52")
+	deleteCmd.Flags().Bool("all", false, "Delete the whole manga")
+	deleteCmd.Flags().Bool("dry-run", false, "List what would be deleted without deleting it")
+	deleteCmd.MarkFlagRequired("title")
+
+	pruneCmd.Flags().Bool("read", false, "Remove chapters that have been read")
+	pruneCmd.Flags().Int("days", 30, "Only remove chapters read more than this many days ago")
+	pruneCmd.Flags().Bool("empty", false, "Remove empty folders")
+	pruneCmd.Flags().Bool("partial", false, "Remove partial downloads")
+	pruneCmd.Flags().Bool("dry-run", false, "List what would be removed without removing it")
+
+	AddSubCommand(deleteCmd)
+	AddSubCommand(pruneCmd)
+}
+
+func removeItems(items []library.PruneItem, dryRun bool) {
+	if len(items) == 0 {
+		fmt.Println("Nothing to remove.")
+		return
+	}
+
+	var total int64
+	for _, item := range items {
+		label := item.Title
+		if item.Chapter != "" {
+			label = fmt.Sprintf("%s chapter %s", item.Title, item.Chapter)
+		}
+
+		if dryRun {
+			fmt.Printf("  %-8s %-50s %10s\n", item.Reason, label, utils.FormatBytes(item.Size))
+			total += item.Size
+			continue
+		}
+
+		freed, err := item.Remove()
+		if err != nil {
+			fmt.Printf("❌ %s: %v\n", label, err)
+			continue
+		}
+		total += freed
+		fmt.Printf("🗑  %-8s %-50s %10s\n", item.Reason, label, utils.FormatBytes(freed))
+	}
+
+	if dryRun {
+		fmt.Printf("\n%d item(s), %s would be freed (dry run)\n", len(items), utils.FormatBytes(total))
+		return
+	}
+	fmt.Printf("\nFreed %s\n", utils.FormatBytes(total))
+}
@@ -1,10 +1,19 @@
 package cmd
 
 import (
+	"context"
 	"fmt"
 	"manga-cli/internals/api"
+	"manga-cli/internals/config"
+	"manga-cli/internals/daemon"
 	"manga-cli/internals/downloader"
+	"manga-cli/internals/library"
+	"manga-cli/internals/progress"
+	"manga-cli/internals/queue"
+	"manga-cli/internals/utils"
 	"os"
+	"slices"
+	"strconv"
 
 	"github.com/spf13/cobra"
 )
@@ -18,6 +27,7 @@ var downloadCmd = &cobra.Command{
 	Run: func(cmd *cobra.Command, args []string) {
 	title, _ := cmd.Flags().GetString("title")
 	chapterNum, _ := cmd.Flags().GetInt("chapter")
+	chaptersStr, _ := cmd.Flags().GetString("chapters")
 	from, _ := cmd.Flags().GetInt("from")
 	to, _ := cmd.Flags().GetInt("to")
 	dataSaver, _ := cmd.Flags().GetBool("data-saver")
@@ -27,47 +37,71 @@ var downloadCmd = &cobra.Command{
 		os.Exit(1)
 	}
 
-	if chapterNum > 0 {
-		chData, err := api.GetChapterIDByNumber(title, chapterNum)
-		if err != nil {
-			fmt.Println("Failed to get chapter:", err)
-			os.Exit(1)
-		}
+	if daemon.Running() && (cmd.Flags().Changed("force") || cmd.Flags().Changed("limit-rate")) {
+		fmt.Println("The download daemon is running and applies its own space check and rate limit,")
+		fmt.Println("so --force and --limit-rate would be ignored. Drop them, or stop the daemon with `manga-cli daemon stop`.")
+		os.Exit(1)
+	}
 
-		err = downloader.DownloadChapter(title, chData.ID, chData.Attributes.Chapter, dataSaver)
-		if err != nil {
-			fmt.Println("Download error:", err)
-			os.Exit(1)
-		}
-		fmt.Println("Downloaded chapter", chapterNum)
+	if err := applyRateLimit(cmd); err != nil {
+		fmt.Println("Error:", err)
+		os.Exit(1)
+	}
+
+	ctx, stop := interruptContext(cmd.Context())
+	defer stop()
+
+	jobs, err := resolveJobs(ctx, title, chapterNum, chaptersStr, from, to, dataSaver)
+	if err != nil {
+		fmt.Println("Failed to get chapters:", err)
+		os.Exit(1)
+	}
+	if len(jobs) == 0 {
+		fmt.Println("No chapters to download.")
 		return
 	}
 
-	if from > 0 && to > 0 && to >= from {
-		chMap, err := api.GetChapterIDsByRange(title, from, to)
+	if daemon.Running() {
+		ids, err := enqueue(jobs)
 		if err != nil {
-			fmt.Println("Failed to fetch chapter range:", err)
+			fmt.Println("Failed to queue chapters:", err)
 			os.Exit(1)
 		}
+		fmt.Printf("Handed %d chapter(s) to the download daemon, see `manga-cli status`\n", len(ids))
+		return
+	}
 
-		for i := from; i <= to; i++ {
-			chID, ok := chMap[i]
-			if !ok {
-				fmt.Printf("Chapter %d not found\n", i)
-				continue
-			}
-
-			err := downloader.DownloadChapter(title, chID, fmt.Sprintf("%d", i), dataSaver)
-			if err != nil {
-				fmt.Printf("Error downloading chapter %d: %v\n", i, err)
-			} else {
-				fmt.Printf("✅ Downloaded chapter %d\n", i)
-			}
-		}
+	force, _ := cmd.Flags().GetBool("force")
+	if !force && !checkSpace(len(jobs), dataSaver) {
+		os.Exit(1)
+	}
+
+	if err := queue.Recover(); err != nil {
+		fmt.Println("Failed to recover download queue:", err)
+		os.Exit(1)
+	}
+
+	ids, err := queue.Add(jobs)
+	if err != nil {
+		fmt.Println("Failed to queue chapters:", err)
+		os.Exit(1)
+	}
+
+	ids, err = skipBusy(ids)
+	if err != nil {
+		fmt.Println("Failed to read download queue:", err)
+		os.Exit(1)
+	}
+	if len(ids) == 0 {
 		return
 	}
 
-	fmt.Println("Please specify either --chapter or --from and --to")
+	closeProgress := useProgress(len(ids))
+	results, err := queue.Process(ctx, queue.OnlyIDs(ids), force)
+	closeProgress()
+	if !printReport(results, len(ids), err) {
+		os.Exit(1)
+	}
 },
 
 }
@@ -77,11 +111,136 @@ func init(){
 	downloadCmd.Flags().Int("from", 0, "Start of chapter range")
 	downloadCmd.Flags().Int("to", 0, "End of chapter range")
 	downloadCmd.Flags().IntVarP(&chapter, "chapter", "c", 0, "Specific chapter number")
+	downloadCmd.Flags().String("chapters", "", "Comma separated chapter numbers")
 	downloadCmd.Flags().StringVarP(&title, "title", "t", "", "Manga title (required)")
 	downloadCmd.Flags().Bool("data-saver", false, "Use data-saver mode for lower quality images")
+	downloadCmd.Flags().String("limit-rate", "", "Maximum download speed, e.g. 500K or 2M")
+	downloadCmd.Flags().Bool("force", false, "Skip the free space and quota check before starting")
 
 	downloadCmd.MarkFlagRequired("title")
 
 	AddSubCommand(downloadCmd)
 }
 
+func resolveJobs(ctx context.Context, title string, chapterNum int, chaptersStr string, from, to int, dataSaver bool) ([]queue.Job, error) {
+	nums, err := utils.ParseChapterFlags(chapterNum, chaptersStr, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	folder := library.ResolveTitle(title)
+
+	if len(nums) == 1 {
+		chData, err := api.GetChapterIDByNumber(ctx, title, nums[0])
+		if err != nil {
+			return nil, err
+		}
+		return []queue.Job{{Title: folder, ChapterID: chData.ID, Chapter: chData.Attributes.Chapter, DataSaver: dataSaver}}, nil
+	}
+
+	chMap, err := api.GetChapterIDs(ctx, title, nums)
+	if err != nil {
+		return nil, err
+	}
+
+	var jobs []queue.Job
+	for _, n := range nums {
+		chID, ok := chMap[n]
+		if !ok {
+			fmt.Printf("Chapter %d not found\n", n)
+			continue
+		}
+		jobs = append(jobs, queue.Job{Title: folder, ChapterID: chID, Chapter: strconv.Itoa(n), DataSaver: dataSaver})
+	}
+	return jobs, nil
+}
+
+// skipBusy reports the jobs another manga-cli process is already
+// downloading and returns the remaining ids.
+func skipBusy(ids []int) ([]int, error) {
+	busy, err := queue.Busy(ids)
+	if err != nil {
+		return nil, err
+	}
+	for _, j := range busy {
+		fmt.Printf("%s chapter %s is already downloading in pid %d\n", j.Title, j.Chapter, j.PID)
+		ids = slices.DeleteFunc(ids, func(id int) bool { return id == j.ID })
+	}
+	return ids, nil
+}
+
+// applyRateLimit sets the download bandwidth cap from --limit-rate, falling
+// back to the limit_rate config key.
+func applyRateLimit(cmd *cobra.Command) error {
+	rate, _ := cmd.Flags().GetString("limit-rate")
+	if rate == "" {
+		if val, err := config.GetConfigOption("limit_rate"); err == nil && val != nil {
+			rate = fmt.Sprintf("%v", val)
+		}
+	}
+
+	limit, err := utils.ParseSize(rate)
+	if err != nil {
+		return fmt.Errorf("invalid rate limit: %w", err)
+	}
+	downloader.SetRateLimit(limit)
+	return nil
+}
+
+// checkSpace makes room under the quota for an upcoming run, reports disk
+// space warnings and whether the run may go ahead.
+func checkSpace(chapters int, dataSaver bool) bool {
+	removed, err := library.MakeRoom(chapters, dataSaver)
+	for _, r := range removed {
+		fmt.Println("Evicted", r)
+	}
+	if err != nil {
+		fmt.Println("Failed to make room:", err)
+		return false
+	}
+
+	warnings, err := library.CheckSpace(chapters, dataSaver)
+	for _, w := range warnings {
+		fmt.Println("⚠", w)
+	}
+	if err != nil {
+		fmt.Println("Refusing to download:", err)
+		return false
+	}
+	return true
+}
+
+// useProgress installs a live progress display for a run of total chapters and
+// returns a function that tears it down again.
+func useProgress(total int) func() {
+	r := progress.New(os.Stdout)
+	r.SetTotal(total)
+	downloader.SetReporter(r)
+	return r.Close
+}
+
+// printReport summarises a queue run and reports whether every chapter made it.
+func printReport(results []queue.Result, total int, err error) bool {
+	done, failed := 0, 0
+	for _, r := range results {
+		if r.Err != nil {
+			failed++
+		} else {
+			done++
+		}
+	}
+
+	switch {
+	case downloader.IsCancelled(err):
+		fmt.Printf("\nInterrupted: %d of %d chapter(s) downloaded, %d failed, %d left in the queue.\n", done, total, failed, total-done-failed)
+		fmt.Println("Resume with `manga-cli queue run`.")
+		return false
+	case err != nil:
+		fmt.Println("Download queue error:", err)
+		return false
+	case failed > 0:
+		fmt.Printf("\n%d of %d chapter(s) downloaded, %d failed, use `manga-cli queue retry` to try again\n", done, total, failed)
+		return false
+	}
+	return true
+}
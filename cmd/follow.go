@@ -0,0 +1,132 @@
+package cmd
+
+import (
+	"fmt"
+	"manga-cli/internals/api"
+	"manga-cli/internals/follows"
+	"manga-cli/internals/history"
+	"manga-cli/internals/library"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+var followCmd = &cobra.Command{
+	Use:   "follow",
+	Short: "Track manga for new chapters without downloading them",
+	Run: func(cmd *cobra.Command, args []string) {
+		_ = cmd.Help()
+	},
+}
+
+var followAddCmd = &cobra.Command{
+	Use:   "add <title>",
+	Short: "Follow a manga by title",
+	Args:  cobra.MinimumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		query := strings.Join(args, " ")
+
+		resp, err := api.GetMangaIDByTitle(cmd.Context(), query)
+		if err != nil {
+			fmt.Println("Error searching manga:", err)
+			os.Exit(1)
+		}
+
+		selected := &resp.Data[0]
+		if len(resp.Data) > 1 {
+			fmt.Printf("\nFound %d manga(s):\n\n", len(resp.Data))
+			for i, manga := range resp.Data {
+				fmt.Printf("%d. %s\n", i+1, manga.Attributes.Title["en"])
+			}
+			fmt.Println()
+			selected = selectManga(resp.Data)
+			if selected == nil {
+				fmt.Println("No manga selected, exiting.")
+				return
+			}
+		}
+
+		followTitle := selected.Attributes.Title["en"]
+		if followTitle == "" {
+			followTitle = query
+		}
+
+		language, _ := updatePreferences()
+		baseline := ""
+		if latest, err := api.GetLatestChapter(cmd.Context(), selected.ID, language); err == nil && latest != nil {
+			baseline = latest.Attributes.Chapter
+		}
+
+		err = follows.Add(follows.Follow{MangaID: selected.ID, Title: followTitle, Baseline: baseline})
+		if err != nil {
+			fmt.Println("Error:", err)
+			os.Exit(1)
+		}
+		fmt.Printf("Following '%s'", followTitle)
+		if baseline != "" {
+			fmt.Printf(" (latest chapter #%s)", baseline)
+		}
+		fmt.Println()
+	},
+}
+
+var followRemoveCmd = &cobra.Command{
+	Use:   "remove <title>",
+	Short: "Stop following a manga",
+	Args:  cobra.MinimumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		f, err := follows.Remove(strings.Join(args, " "))
+		if err != nil {
+			fmt.Println("Error:", err)
+			os.Exit(1)
+		}
+		fmt.Printf("Unfollowed '%s'\n", f.Title)
+	},
+}
+
+var followListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List followed manga with their latest chapters",
+	Run: func(cmd *cobra.Command, args []string) {
+		list, err := follows.Load()
+		if err != nil {
+			fmt.Println("Error:", err)
+			os.Exit(1)
+		}
+		if len(list) == 0 {
+			fmt.Println("Not following any manga. Use `manga-cli follow add <title>`.")
+			return
+		}
+
+		idx, _ := library.LoadIndex()
+		hist, _ := history.Load()
+		language, _ := updatePreferences()
+
+		fmt.Printf("%-40s %-10s %-10s %-10s\n", "TITLE", "LATEST", "LOCAL", "LAST READ")
+		for _, f := range list {
+			latest := "?"
+			if ch, err := api.GetLatestChapter(cmd.Context(), f.MangaID, language); err == nil && ch != nil {
+				latest = ch.Attributes.Chapter
+			}
+
+			local, lastRead := "-", "-"
+			if idx != nil {
+				if e := idx.FindByMangaID(f.MangaID); e != nil {
+					if e.Latest != "" {
+						local = e.Latest
+					}
+					if ch, _ := hist.Last(e.Title); ch != "" {
+						lastRead = ch
+					}
+				}
+			}
+			fmt.Printf("%-40s %-10s %-10s %-10s\n", f.Title, latest, local, lastRead)
+		}
+	},
+}
+
+func init() {
+	followCmd.AddCommand(followAddCmd, followRemoveCmd, followListCmd)
+	AddSubCommand(followCmd)
+}
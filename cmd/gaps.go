@@ -0,0 +1,72 @@
+package cmd
+
+import (
+	"fmt"
+	"manga-cli/internals/library"
+	"manga-cli/internals/queue"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+var gapsCmd = &cobra.Command{
+	Use:   "gaps",
+	Short: "Find chapters missing from a downloaded manga and optionally queue them",
+	Run: func(cmd *cobra.Command, args []string) {
+		gTitle, _ := cmd.Flags().GetString("title")
+		fill, _ := cmd.Flags().GetBool("fill")
+		includeNewer, _ := cmd.Flags().GetBool("all")
+		dataSaver, _ := cmd.Flags().GetBool("data-saver")
+
+		gTitle = library.ResolveTitle(gTitle)
+		language, groups := updatePreferences()
+
+		report, err := library.FindGaps(cmd.Context(), gTitle, language, groups, includeNewer)
+		if err != nil {
+			fmt.Println("Error:", err)
+			os.Exit(1)
+		}
+
+		if len(report.Missing) == 0 {
+			fmt.Printf("No missing chapters for '%s'.\n", gTitle)
+		} else {
+			fmt.Printf("Missing chapters for '%s' (%d):\n", gTitle, len(report.Missing))
+			for _, ch := range report.Missing {
+				group := ch.GroupName()
+				if group == "" {
+					group = "unknown group"
+				}
+				fmt.Printf("   #%-8s %-40s [%s]\n", ch.Attributes.Chapter, ch.Attributes.Title, group)
+			}
+		}
+
+		if len(report.Unavailable) > 0 {
+			fmt.Printf("\nNot available in '%s' on MangaDex: %s\n", language, strings.Join(library.FormatGaps(report.Unavailable), ", "))
+		}
+
+		if !fill || len(report.Missing) == 0 {
+			return
+		}
+
+		var jobs []queue.Job
+		for _, ch := range report.Missing {
+			jobs = append(jobs, queue.Job{Title: gTitle, ChapterID: ch.ID, Chapter: ch.Attributes.Chapter, DataSaver: dataSaver})
+		}
+		ids, err := enqueue(jobs)
+		if err != nil {
+			fmt.Println("Failed to queue chapters:", err)
+			os.Exit(1)
+		}
+		fmt.Printf("\nQueued %d chapter(s), run `manga-cli queue run` to download them.\n", len(ids))
+	},
+}
+
+func init() {
+	gapsCmd.Flags().StringP("title", "t", "", "Manga title (required)")
+	gapsCmd.Flags().Bool("fill", false, "Queue every missing chapter for download")
+	gapsCmd.Flags().Bool("all", false, "Also include chapters newer than the latest downloaded one")
+	gapsCmd.Flags().Bool("data-saver", false, "Use data-saver mode for queued chapters")
+	gapsCmd.MarkFlagRequired("title")
+	AddSubCommand(gapsCmd)
+}
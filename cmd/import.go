@@ -0,0 +1,156 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"manga-cli/internals/api"
+	"manga-cli/internals/importer"
+	"manga-cli/internals/library"
+	"os"
+	"strconv"
+
+	"github.com/spf13/cobra"
+)
+
+type importMatch struct {
+	title   string
+	mangaID string
+	titles  map[string]string
+}
+
+var importCmd = &cobra.Command{
+	Use:   "import <path>",
+	Short: "Import CBZ/CBR archives and image folders into the library",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		iTitle, _ := cmd.Flags().GetString("title")
+		match, _ := cmd.Flags().GetBool("match")
+		dryRun, _ := cmd.Flags().GetBool("dry-run")
+		keepArchives, _ := cmd.Flags().GetBool("keep-archives")
+
+		iChapter, _ := cmd.Flags().GetString("chapter")
+
+		items, err := importer.Scan(args[0])
+		if err != nil {
+			fmt.Println("Warning:", err)
+		}
+		if len(items) == 0 {
+			fmt.Println("Nothing to import.")
+			return
+		}
+
+		if iChapter != "" {
+			n, ok := library.ChapterNumber(iChapter)
+			if !ok {
+				fmt.Printf("Invalid chapter '%s'\n", iChapter)
+				os.Exit(1)
+			}
+			if len(items) > 1 {
+				fmt.Printf("--chapter needs a single archive or folder, %s holds %d\n", args[0], len(items))
+				os.Exit(1)
+			}
+			items[0].Chapter = strconv.FormatFloat(n, 'f', -1, 64)
+		}
+
+		idx, err := library.LoadIndex()
+		if err != nil {
+			fmt.Println("Failed to load library index:", err)
+			os.Exit(1)
+		}
+
+		matches := map[string]*importMatch{}
+		resolve := func(title string) *importMatch {
+			if m, ok := matches[title]; ok {
+				return m
+			}
+			m := &importMatch{title: library.ResolveTitle(title)}
+			if man, err := library.LoadManifest(m.title); err == nil && man.MangaID != "" {
+				m.mangaID = man.MangaID
+			} else if match {
+				resp, err := api.GetMangaIDByTitle(cmd.Context(), title)
+				if err != nil {
+					fmt.Printf("⚠ no MangaDex match for '%s': %v\n", title, err)
+				} else if picked := pickMatch(title, resp.Data); picked != nil {
+					m.mangaID = picked.ID
+					m.titles = picked.Attributes.Title
+					if e := idx.FindByMangaID(m.mangaID); e != nil {
+						m.title = e.Title
+					}
+				} else {
+					fmt.Printf("⚠ '%s' left unmatched\n", title)
+				}
+			}
+			matches[title] = m
+			return m
+		}
+
+		imported, skipped, failed := 0, 0, 0
+		for _, it := range items {
+			if iTitle != "" {
+				it.Title = iTitle
+			}
+			if it.Title == "" {
+				fmt.Printf("❌ %s: cannot tell the title, use --title\n", it.Source)
+				failed++
+				continue
+			}
+
+			m := resolve(it.Title)
+			it.Title = m.title
+			if dryRun {
+				fmt.Printf("   %s  <-  %s\n", it, it.Source)
+				continue
+			}
+
+			pages, err := importer.Import(it, m.mangaID, m.titles, keepArchives)
+			switch {
+			case errors.Is(err, importer.ErrExists):
+				fmt.Printf("⏭ %s already in library\n", it)
+				skipped++
+			case err != nil:
+				fmt.Printf("❌ %s: %v\n", it.Source, err)
+				failed++
+			default:
+				fmt.Printf("✅ %s (%d pages)\n", it, pages)
+				imported++
+			}
+		}
+
+		if dryRun {
+			fmt.Printf("\n%d chapter(s) would be imported.\n", len(items))
+			return
+		}
+		fmt.Printf("\nImported %d, skipped %d, failed %d.\n", imported, skipped, failed)
+		if failed > 0 {
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	importCmd.Flags().StringP("title", "t", "", "Use this title for everything imported")
+	importCmd.Flags().String("chapter", "", "Chapter number of a single archive or folder, e.g. for volume-only names")
+	importCmd.Flags().Bool("match", false, "Look up titles on MangaDex to record their IDs")
+	importCmd.Flags().Bool("keep-archives", false, "Store CBZ/CBR files as they are and read them in place")
+	importCmd.Flags().Bool("dry-run", false, "Show what would be imported without copying anything")
+	AddSubCommand(importCmd)
+}
+
+// pickMatch lets the user confirm the MangaDex search result for an imported
+// title, or choose among several. It returns nil when none is accepted.
+func pickMatch(title string, results []api.MangaData) *api.MangaData {
+	if len(results) == 1 {
+		found := results[0].Attributes.Title["en"]
+		if confirm(fmt.Sprintf("Match '%s' to MangaDex '%s'? [y/N]: ", title, found)) {
+			return &results[0]
+		}
+		return nil
+	}
+
+	fmt.Printf("\nMangaDex results for '%s':\n\n", title)
+	for i, manga := range results {
+		fmt.Printf("%d. %s\n", i+1, manga.Attributes.Title["en"])
+	}
+	fmt.Println()
+	return selectManga(results)
+}
@@ -1,11 +1,16 @@
 package cmd
 
 import (
+	"encoding/json"
 	"fmt"
 	"manga-cli/internals/config"
+	"manga-cli/internals/history"
+	"manga-cli/internals/library"
 	"manga-cli/internals/listUtils"
 	"os"
 	"path/filepath"
+	"sort"
+	"strings"
 
 	"github.com/spf13/cobra"
 )
@@ -17,6 +22,7 @@ var listCmd = &cobra.Command{
 		pathFlag, _ := cmd.Flags().GetString("path")
 		title, _ := cmd.Flags().GetString("title")
 		chapter, _ := cmd.Flags().GetString("chapter")
+		asJSON, _ := cmd.Flags().GetBool("json")
 
 		var basePath string
 		if pathFlag != "" {
@@ -35,7 +41,7 @@ var listCmd = &cobra.Command{
 			return
 		}
 
-		if title == "" {
+		if title == "" && pathFlag != "" {
 			fmt.Println("Available manga:")
 			if err := listUtils.ListTopLevelFolders(basePath); err != nil {
 				fmt.Println("Error:", err)
@@ -43,6 +49,36 @@ var listCmd = &cobra.Command{
 			return
 		}
 
+		if title == "" {
+			stats, err := library.Stats()
+			if err != nil {
+				fmt.Println("Error:", err)
+				return
+			}
+
+			filter, _ := cmd.Flags().GetString("filter")
+			sortBy, _ := cmd.Flags().GetString("sort")
+			reverse, _ := cmd.Flags().GetBool("reverse")
+			stats = filterStats(stats, filter)
+			if err := sortStats(stats, sortBy, reverse); err != nil {
+				fmt.Println("Error:", err)
+				return
+			}
+
+			if asJSON {
+				printJSON(stats)
+				return
+			}
+			if len(stats) == 0 {
+				fmt.Println("No manga downloaded yet.")
+				return
+			}
+			listUtils.PrintStatsTable(stats)
+			return
+		}
+
+		title = library.ResolveTitle(title)
+
 		mangaPath := filepath.Join(basePath, title)
 		if _, err := os.Stat(mangaPath); os.IsNotExist(err) {
 			fmt.Printf("Manga title '%s' not found in downloads.\n", title)
@@ -50,6 +86,19 @@ var listCmd = &cobra.Command{
 		}
 
 		if chapter == "" {
+			if m, err := library.LoadManifest(title); err == nil && pathFlag == "" {
+				if asJSON {
+					printJSON(m)
+					return
+				}
+				fmt.Printf("Chapters for manga '%s':\n", title)
+				hist, err := history.Load()
+				if err != nil {
+					fmt.Println("Warning:", err)
+				}
+				listUtils.ListManifestChapters(m, hist)
+				return
+			}
 			fmt.Printf("Chapters for manga '%s':\n", title)
 			if err := listUtils.ListTopLevelFolders(mangaPath); err != nil {
 				fmt.Println("Error:", err)
@@ -74,5 +123,58 @@ func init() {
 	listCmd.Flags().String("path", "", "Override the download path")
 	listCmd.Flags().String("title", "", "Manga title to list chapters")
 	listCmd.Flags().String("chapter", "", "Chapter number/title to list images")
+	listCmd.Flags().String("sort", "title", "Sort manga by title, chapters, size, updated or progress")
+	listCmd.Flags().Bool("reverse", false, "Reverse the sort order")
+	listCmd.Flags().String("filter", "", "Only show manga whose title contains this text")
+	listCmd.Flags().Bool("json", false, "Print JSON instead of a table")
 	AddSubCommand(listCmd)
 }
+
+func filterStats(stats []library.MangaStats, filter string) []library.MangaStats {
+	if filter == "" {
+		return stats
+	}
+	filter = strings.ToLower(filter)
+	var out []library.MangaStats
+	for _, s := range stats {
+		if strings.Contains(strings.ToLower(s.Title), filter) {
+			out = append(out, s)
+		}
+	}
+	return out
+}
+
+func sortStats(stats []library.MangaStats, by string, reverse bool) error {
+	var less func(a, b library.MangaStats) bool
+	switch by {
+	case "title", "":
+		less = func(a, b library.MangaStats) bool { return strings.ToLower(a.Title) < strings.ToLower(b.Title) }
+	case "chapters":
+		less = func(a, b library.MangaStats) bool { return a.Chapters < b.Chapters }
+	case "size":
+		less = func(a, b library.MangaStats) bool { return a.Size < b.Size }
+	case "updated":
+		less = func(a, b library.MangaStats) bool { return a.LastDownload.Before(b.LastDownload) }
+	case "progress":
+		less = func(a, b library.MangaStats) bool { return a.Progress() < b.Progress() }
+	default:
+		return fmt.Errorf("unknown sort key '%s'", by)
+	}
+
+	sort.SliceStable(stats, func(i, j int) bool {
+		if reverse {
+			return less(stats[j], stats[i])
+		}
+		return less(stats[i], stats[j])
+	})
+	return nil
+}
+
+func printJSON(v any) {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		fmt.Println("Error:", err)
+		return
+	}
+	fmt.Println(string(data))
+}
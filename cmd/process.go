@@ -0,0 +1,67 @@
+package cmd
+
+import (
+	"fmt"
+	"manga-cli/internals/imageproc"
+	"manga-cli/internals/library"
+	"os"
+
+	"github.com/spf13/cobra"
+)
+
+var processCmd = &cobra.Command{
+	Use:   "process",
+	Short: "Show or set the image transforms applied to a manga's downloads",
+	Long: `Transforms are given as a comma separated list:
+
+  format=jpeg|png   convert pages (WebP input is supported)
+  quality=N         JPEG quality, 1-100
+  max-height=N      shrink pages taller than N pixels
+  grayscale         convert to grayscale for e-ink readers
+  autocrop          trim white borders
+  split=rtl|ltr     split double-page spreads into two pages
+
+The "process" config key sets the default for every manga.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		pTitle, _ := cmd.Flags().GetString("title")
+		spec, _ := cmd.Flags().GetString("set")
+		clearSpec, _ := cmd.Flags().GetBool("clear")
+
+		pTitle = library.ResolveTitle(pTitle)
+
+		switch {
+		case clearSpec:
+			spec = ""
+		case spec != "":
+			if _, err := imageproc.ParseOptions(spec); err != nil {
+				fmt.Println("Error:", err)
+				os.Exit(1)
+			}
+		default:
+			current := library.ProcessingSpec(pTitle)
+			if current == "" {
+				current = "(none)"
+			}
+			fmt.Printf("%s: %s\n", pTitle, current)
+			return
+		}
+
+		if err := library.SetProcessingSpec(pTitle, spec); err != nil {
+			fmt.Println("Error:", err)
+			os.Exit(1)
+		}
+		if spec == "" {
+			fmt.Printf("Cleared image transforms for '%s'\n", pTitle)
+		} else {
+			fmt.Printf("Set image transforms for '%s': %s\n", pTitle, spec)
+		}
+	},
+}
+
+func init() {
+	processCmd.Flags().StringP("title", "t", "", "Manga title (required)")
+	processCmd.Flags().String("set", "", "Transforms to apply to new downloads")
+	processCmd.Flags().Bool("clear", false, "Remove the per-manga setting and use the global one")
+	processCmd.MarkFlagRequired("title")
+	AddSubCommand(processCmd)
+}
@@ -0,0 +1,271 @@
+package cmd
+
+import (
+	"fmt"
+	"manga-cli/internals/daemon"
+	"manga-cli/internals/queue"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+var queueCmd = &cobra.Command{
+	Use:   "queue",
+	Short: "Manage the persistent download queue",
+	Run: func(cmd *cobra.Command, args []string) {
+		_ = cmd.Help()
+	},
+}
+
+var queueAddCmd = &cobra.Command{
+	Use:   "add",
+	Short: "Queue chapters for download without starting them",
+	Run: func(cmd *cobra.Command, args []string) {
+		qTitle, _ := cmd.Flags().GetString("title")
+		chapterNum, _ := cmd.Flags().GetInt("chapter")
+		chaptersStr, _ := cmd.Flags().GetString("chapters")
+		from, _ := cmd.Flags().GetInt("from")
+		to, _ := cmd.Flags().GetInt("to")
+		dataSaver, _ := cmd.Flags().GetBool("data-saver")
+
+		jobs, err := resolveJobs(cmd.Context(), qTitle, chapterNum, chaptersStr, from, to, dataSaver)
+		if err != nil {
+			fmt.Println("Failed to get chapters:", err)
+			os.Exit(1)
+		}
+
+		ids, err := enqueue(jobs)
+		if err != nil {
+			fmt.Println("Failed to queue chapters:", err)
+			os.Exit(1)
+		}
+		fmt.Printf("Queued %d chapter(s)\n", len(ids))
+	},
+}
+
+var queueListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "Show queued jobs and their status",
+	Run: func(cmd *cobra.Command, args []string) {
+		q, err := loadQueue()
+		if err != nil {
+			fmt.Println("Error:", err)
+			os.Exit(1)
+		}
+		if len(q.Jobs) == 0 {
+			fmt.Println("Download queue is empty.")
+			return
+		}
+
+		fmt.Printf("%-5s %-8s %-30s %-8s %-8s %s\n", "ID", "STATUS", "TITLE", "CHAPTER", "ATTEMPTS", "ERROR")
+		for _, j := range q.Jobs {
+			fmt.Printf("%-5d %-8s %-30s %-8s %-8d %s\n", j.ID, j.Status, j.Title, j.Chapter, j.Attempts, j.Error)
+		}
+
+		counts := q.Counts()
+		fmt.Printf("\n%d pending, %d paused, %d failed, %d done\n",
+			counts[queue.StatusPending], counts[queue.StatusPaused], counts[queue.StatusFailed], counts[queue.StatusDone])
+	},
+}
+
+var queuePauseCmd = &cobra.Command{
+	Use:   "pause [id...]",
+	Short: "Pause pending jobs (all if no IDs are given)",
+	Run: func(cmd *cobra.Command, args []string) {
+		runTransition(args, daemon.ActionPause, queue.Pause, "Paused")
+	},
+}
+
+var queueResumeCmd = &cobra.Command{
+	Use:   "resume [id...]",
+	Short: "Resume paused jobs (all if no IDs are given)",
+	Run: func(cmd *cobra.Command, args []string) {
+		runTransition(args, daemon.ActionResume, queue.Resume, "Resumed")
+	},
+}
+
+var queueRetryCmd = &cobra.Command{
+	Use:   "retry [id...]",
+	Short: "Mark failed jobs as pending again (all if no IDs are given)",
+	Run: func(cmd *cobra.Command, args []string) {
+		runTransition(args, daemon.ActionRetry, queue.Retry, "Retrying")
+	},
+}
+
+var queueClearCmd = &cobra.Command{
+	Use:   "clear",
+	Short: "Remove finished jobs from the queue",
+	Run: func(cmd *cobra.Command, args []string) {
+		all, _ := cmd.Flags().GetBool("all")
+
+		var n int
+		var err error
+		if daemon.Running() {
+			var resp *daemon.Response
+			resp, err = daemon.Call(daemon.Request{Action: daemon.ActionClear, All: all})
+			if resp != nil {
+				n = resp.Count
+			}
+		} else {
+			n, err = queue.Clear(all)
+		}
+		if err != nil {
+			fmt.Println("Error:", err)
+			os.Exit(1)
+		}
+		fmt.Printf("Removed %d job(s)\n", n)
+	},
+}
+
+var queueRunCmd = &cobra.Command{
+	Use:   "run",
+	Short: "Download every pending job in the queue",
+	Run: func(cmd *cobra.Command, args []string) {
+		if daemon.Running() {
+			fmt.Println("The daemon is processing the queue, see `manga-cli status`.")
+			return
+		}
+
+		if err := queue.Recover(); err != nil {
+			fmt.Println("Error:", err)
+			os.Exit(1)
+		}
+
+		q, err := queue.Load()
+		if err != nil {
+			fmt.Println("Error:", err)
+			os.Exit(1)
+		}
+		pending := q.Counts()[queue.StatusPending]
+		if pending == 0 {
+			fmt.Println("No pending jobs.")
+			return
+		}
+
+		if err := applyRateLimit(cmd); err != nil {
+			fmt.Println("Error:", err)
+			os.Exit(1)
+		}
+
+		window, err := queue.ConfiguredWindow()
+		if err != nil {
+			fmt.Println("Error:", err)
+			os.Exit(1)
+		}
+		if ignore, _ := cmd.Flags().GetBool("ignore-window"); ignore {
+			window = nil
+		}
+
+		ctx, stop := interruptContext(cmd.Context())
+		defer stop()
+
+		if wait := window.Until(time.Now()); wait > 0 {
+			fmt.Printf("Waiting %s for the download window %s...\n", wait.Round(time.Minute), window)
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(wait):
+			}
+		}
+
+		if !checkSpace(pending, false) {
+			os.Exit(1)
+		}
+
+		closeProgress := useProgress(pending)
+		results, err := queue.Process(ctx, window.InWindow, false)
+		closeProgress()
+		if !printReport(results, pending, err) {
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	queueAddCmd.Flags().StringP("title", "t", "", "Manga title (required)")
+	queueAddCmd.Flags().IntP("chapter", "c", 0, "Specific chapter number")
+	queueAddCmd.Flags().String("chapters", "", "Comma separated chapter numbers")
+	queueAddCmd.Flags().Int("from", 0, "Start of chapter range")
+	queueAddCmd.Flags().Int("to", 0, "End of chapter range")
+	queueAddCmd.Flags().Bool("data-saver", false, "Use data-saver mode for lower quality images")
+	queueAddCmd.MarkFlagRequired("title")
+
+	queueClearCmd.Flags().Bool("all", false, "Also remove pending, paused and failed jobs")
+
+	queueRunCmd.Flags().String("limit-rate", "", "Maximum download speed, e.g. 500K or 2M")
+	queueRunCmd.Flags().Bool("ignore-window", false, "Run now even outside the configured download window")
+
+	queueCmd.AddCommand(queueAddCmd, queueListCmd, queuePauseCmd, queueResumeCmd, queueRetryCmd, queueClearCmd, queueRunCmd)
+	AddSubCommand(queueCmd)
+}
+
+func parseJobIDs(args []string) ([]int, error) {
+	var ids []int
+	for _, a := range args {
+		id, err := strconv.Atoi(a)
+		if err != nil {
+			return nil, fmt.Errorf("invalid job id: %s", a)
+		}
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
+
+func runTransition(args []string, action string, fn func([]int) (int, error), verb string) {
+	ids, err := parseJobIDs(args)
+	if err != nil {
+		fmt.Println("Error:", err)
+		os.Exit(1)
+	}
+
+	var n int
+	if daemon.Running() {
+		var resp *daemon.Response
+		resp, err = daemon.Call(daemon.Request{Action: action, IDs: ids})
+		if resp != nil {
+			n = resp.Count
+		}
+	} else {
+		n, err = fn(ids)
+	}
+	if err != nil {
+		fmt.Println("Error:", err)
+		os.Exit(1)
+	}
+	fmt.Printf("%s %d job(s)\n", verb, n)
+}
+
+func enqueue(jobs []queue.Job) ([]int, error) {
+	if daemon.Running() {
+		resp, err := daemon.Call(daemon.Request{Action: daemon.ActionAdd, Jobs: jobs})
+		if err != nil {
+			return nil, err
+		}
+		return resp.IDs, nil
+	}
+	return queue.Add(jobs)
+}
+
+func loadQueue() (*queue.Queue, error) {
+	if daemon.Running() {
+		resp, err := daemon.Call(daemon.Request{Action: daemon.ActionList})
+		if err != nil {
+			return nil, err
+		}
+		return resp.Queue, nil
+	}
+	return queue.Load()
+}
+
+func printQueueSummary() {
+	q, err := queue.Load()
+	if err != nil {
+		fmt.Println("Queue: error:", err)
+		return
+	}
+	counts := q.Counts()
+	fmt.Printf("Queue: %d pending, %d paused, %d failed, %d done\n",
+		counts[queue.StatusPending], counts[queue.StatusPaused], counts[queue.StatusFailed], counts[queue.StatusDone])
+}
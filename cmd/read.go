@@ -1,11 +1,18 @@
 package cmd
 
 import (
+	"context"
 	"fmt"
 	"manga-cli/internals/config"
+	"manga-cli/internals/history"
+	"manga-cli/internals/library"
+	"manga-cli/internals/queue"
 	readerUtil "manga-cli/internals/reader"
 	"manga-cli/internals/utils"
 	"os"
+	"slices"
+	"strconv"
+	"time"
 
 	"github.com/spf13/cobra"
 )
@@ -13,44 +20,74 @@ import (
 
 
 var readCmd = &cobra.Command{
-	Use:   "read",
+	Use:   "read [folder or archive]",
 	Short: "Read a downloaded manga from local storage",
+	Args:  cobra.MaximumNArgs(1),
 	Run: func(cmd *cobra.Command, args []string) {
-		if title == "" || chapter == 0 {
+		resume, _ := cmd.Flags().GetBool("continue")
+		if len(args) == 0 && (title == "" || (chapter == 0 && !resume)) {
 			fmt.Println("Usage: manga-cli read --title 'One Piece' --chapter 1012")
+			fmt.Println("       manga-cli read --title 'One Piece' --continue")
+			fmt.Println("       manga-cli read ~/Comics/volume01.cbz")
 			os.Exit(1)
 		}
 
-		width, _ := cmd.Flags().GetInt("width")
-		height, _ := cmd.Flags().GetInt("height")
+		opts, err := readingOptions(cmd)
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
 
-		if width == 0 || height == 0 {
-			cfg, _ := config.GetAllConfig()
+		if stream, _ := cmd.Flags().GetBool("stream"); stream {
+			if len(args) == 1 || title == "" || chapter == 0 {
+				fmt.Println("Usage: manga-cli read --title 'One Piece' --chapter 1012 --stream")
+				os.Exit(1)
+			}
+			dataSaver, _ := cmd.Flags().GetBool("data-saver")
+			opts.DataSaver = dataSaver
+			streamChapter(cmd.Context(), title, strconv.Itoa(chapter), opts)
+			return
+		}
 
-			if width == 0 {
-				if val, ok := cfg["width"]; ok {
-					if w, ok := val.(float64); ok {
-						width = int(w)
-					}
-				}
+		var path string
+		page := 0
+		if len(args) == 1 {
+			path = args[0]
+		} else if resume {
+			p, pg, err := resumePoint(library.ResolveTitle(title))
+			if err != nil {
+				fmt.Println(err)
+				os.Exit(1)
+			}
+			path, page = p, pg
+		} else {
+			p, err := utils.GetPathByTitleAndChapter(library.ResolveTitle(title), chapter)
+			if err != nil {
+				fmt.Println(err)
+				os.Exit(1)
 			}
+			path = p
+		}
+
+		ctx, stop := interruptContext(cmd.Context())
+		defer stop()
 
-			if height == 0 {
-				if val, ok := cfg["height"]; ok {
-					if h, ok := val.(float64); ok {
-						height = int(h)
-					}
-				}
+		if mangaTitle, _ := readerUtil.ChapterOf(path); opts.Mode == readerUtil.ModeAuto {
+			if _, err := library.LoadManifest(mangaTitle); mangaTitle != "" && err == nil {
+				opts.Mode = resolveMode(ctx, opts.Mode, mangaTitle)
+			} else {
+				// Folders outside the library are not looked up on MangaDex.
+				opts.Mode = readerUtil.ModePage
 			}
 		}
 
-		path, err := utils.GetPathByTitleAndChapter(title, chapter)
-		if err != nil {
-			fmt.Println(err)
-			os.Exit(1)
+		opts.Page = page
+		if download, _ := cmd.Flags().GetBool("download"); download {
+			dataSaver, _ := cmd.Flags().GetBool("data-saver")
+			opts.Fetch = fetchAdjacent(ctx, false, dataSaver)
 		}
 
-		if err := readerUtil.StartReader(path, width, height); err != nil {
+		if err := readerUtil.Open(path, opts); err != nil {
 			fmt.Println("Failed to start reader:", err)
 			os.Exit(1)
 		}
@@ -58,14 +95,227 @@ var readCmd = &cobra.Command{
 
 }
 
+// resumePoint returns where to continue reading a manga: the page the last
+// opened chapter was left at, or the start of the following downloaded
+// chapter when that one was finished.
+func resumePoint(title string) (string, int, error) {
+	hist, err := history.Load()
+	if err != nil {
+		return "", 0, err
+	}
+	ch, st := hist.Last(title)
+	if st == nil {
+		return "", 0, fmt.Errorf("nothing read yet in '%s', start with --chapter", title)
+	}
+
+	page := st.Page
+	if st.Completed {
+		chapters, _ := library.LocalChapters(title)
+		if i := slices.Index(chapters, ch); i >= 0 && i+1 < len(chapters) {
+			ch, page = chapters[i+1], 1
+		}
+	}
+
+	path, err := library.ChapterPath(title, ch)
+	if err != nil {
+		return "", 0, fmt.Errorf("chapter %s of '%s' is no longer downloaded", ch, title)
+	}
+	fmt.Printf("Continuing '%s' at chapter %s, page %d\n", title, ch, max(page, 1))
+	return path, page, nil
+}
+
+// streamChapter reads a chapter from MangaDex without adding it to the
+// library. Turning past either end streams the neighbouring chapter, or reads
+// it from disk when the library has it. A title the library knows under
+// another name is resolved to its folder so both are found.
+func streamChapter(parent context.Context, title, number string, opts readerUtil.Options) {
+	ctx, stop := interruptContext(parent)
+	defer stop()
+
+	title = library.ResolveTitle(title)
+
+	language, groups := updatePreferences()
+	ch, err := library.RemoteChapter(ctx, title, number, language, groups)
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+
+	opts.Context = ctx
+	opts.Fetch = fetchAdjacent(ctx, true, opts.DataSaver)
+	opts.Mode = resolveMode(ctx, opts.Mode, title)
+	if err := readerUtil.Read(readerUtil.Chapter{Title: title, Number: ch.Attributes.Chapter, ID: ch.ID}, opts); err != nil {
+		fmt.Println("Failed to start reader:", err)
+		os.Exit(1)
+	}
+}
+
+// readingOptions returns the reader size and layout from the --width,
+// --height, --mode, --direction and --fit flags, falling back to the config
+// keys of the same names and "cover". The mode may still be auto.
+func readingOptions(cmd *cobra.Command) (readerUtil.Options, error) {
+	opts := readerUtil.Options{Cover: true}
+
+	opts.Width, _ = cmd.Flags().GetInt("width")
+	if opts.Width == 0 {
+		opts.Width = configInt("width")
+	}
+	opts.Height, _ = cmd.Flags().GetInt("height")
+	if opts.Height == 0 {
+		opts.Height = configInt("height")
+	}
+
+	mode, _ := cmd.Flags().GetString("mode")
+	if mode == "" {
+		if val, err := config.GetConfigOption("mode"); err == nil && val != nil {
+			mode = fmt.Sprintf("%v", val)
+		}
+	}
+	direction, _ := cmd.Flags().GetString("direction")
+	if direction == "" {
+		if val, err := config.GetConfigOption("direction"); err == nil && val != nil {
+			direction = fmt.Sprintf("%v", val)
+		}
+	}
+	fit, _ := cmd.Flags().GetString("fit")
+	if fit == "" {
+		if val, err := config.GetConfigOption("fit"); err == nil && val != nil {
+			fit = fmt.Sprintf("%v", val)
+		}
+	}
+	if val, err := config.GetConfigOption("cover"); err == nil {
+		switch v := val.(type) {
+		case bool:
+			opts.Cover = v
+		case string:
+			if b, err := strconv.ParseBool(v); err == nil {
+				opts.Cover = b
+			}
+		}
+	}
+
+	var err error
+	if opts.Mode, err = readerUtil.ParseMode(mode); err != nil {
+		return opts, err
+	}
+	if opts.Direction, err = readerUtil.ParseDirection(direction); err != nil {
+		return opts, err
+	}
+	opts.Fit, err = readerUtil.ParseFit(fit)
+	return opts, err
+}
+
+// configInt reads a numeric config option, stored as a number or as the
+// text given to `config set`. Missing or invalid values are 0.
+func configInt(key string) int {
+	val, err := config.GetConfigOption(key)
+	if err != nil {
+		return 0
+	}
+	switch v := val.(type) {
+	case float64:
+		return int(v)
+	case int:
+		return v
+	case string:
+		n, _ := strconv.Atoi(v)
+		return n
+	}
+	return 0
+}
+
+// modeLookupTimeout bounds the MangaDex lookup of the auto reading mode, so
+// reading offline does not wait for the network.
+const modeLookupTimeout = 3 * time.Second
+
+// resolveMode settles the auto reading mode: manga tagged Long Strip on
+// MangaDex are read as webtoons, everything else page by page.
+func resolveMode(ctx context.Context, mode, title string) string {
+	if mode != readerUtil.ModeAuto {
+		return mode
+	}
+	ctx, cancel := context.WithTimeout(ctx, modeLookupTimeout)
+	defer cancel()
+	if long, err := library.IsLongStrip(ctx, title); err == nil && long {
+		return readerUtil.ModeWebtoon
+	}
+	return readerUtil.ModePage
+}
+
+// fetchAdjacent returns a reader hook that looks up the chapter after (or
+// before) the current one on MangaDex. A chapter the library already has is
+// read from disk; otherwise it is streamed, or downloaded first when stream
+// is unset. Downloads go through the queue, so they get the same free space
+// and quota check as the download command.
+func fetchAdjacent(ctx context.Context, stream, dataSaver bool) func(title, chapter string, forward bool) (readerUtil.Chapter, error) {
+	language, groups := updatePreferences()
+	return func(title, chapter string, forward bool) (readerUtil.Chapter, error) {
+		ch, err := library.AdjacentRemoteChapter(ctx, title, chapter, forward, language, groups)
+		if err != nil {
+			return readerUtil.Chapter{}, err
+		}
+		if ch == nil {
+			return readerUtil.Chapter{}, fmt.Errorf("none on MangaDex")
+		}
+
+		number := ch.Attributes.Chapter
+		if path, err := library.ChapterPath(title, number); err == nil {
+			return readerUtil.LocalChapter(path), nil
+		}
+		if stream {
+			return readerUtil.Chapter{Title: title, Number: number, ID: ch.ID}, nil
+		}
+		job := queue.Job{Title: title, ChapterID: ch.ID, Chapter: number, DataSaver: dataSaver}
+		if err := downloadNow(ctx, job); err != nil {
+			return readerUtil.Chapter{}, err
+		}
+		path, err := library.ChapterPath(title, number)
+		if err != nil {
+			return readerUtil.Chapter{}, err
+		}
+		return readerUtil.LocalChapter(path), nil
+	}
+}
+
+// downloadNow queues a single chapter and downloads it right away.
+func downloadNow(ctx context.Context, job queue.Job) error {
+	ids, err := queue.Add([]queue.Job{job})
+	if err != nil {
+		return err
+	}
+	busy, err := queue.Busy(ids)
+	if err != nil {
+		return err
+	}
+	if len(busy) > 0 {
+		return fmt.Errorf("chapter %s is already downloading in pid %d", job.Chapter, busy[0].PID)
+	}
+	closeProgress := useProgress(1)
+	results, err := queue.Process(ctx, queue.OnlyIDs(ids), false)
+	closeProgress()
+	if err != nil {
+		return err
+	}
+	for _, r := range results {
+		if r.Err != nil {
+			return r.Err
+		}
+	}
+	return nil
+}
+
 func init(){
 	readCmd.Flags().StringVarP(&title, "title", "t", "", "Manga title (required)")
 	readCmd.Flags().IntVarP(&chapter, "chapter", "c", 0, "Chapter number (required)")
-	readCmd.Flags().Int("width", 0, "Width of image viewer")
-	readCmd.Flags().Int("height", 0, "Height of image viewer")
-	
-	readCmd.MarkFlagRequired("title")
-	readCmd.MarkFlagRequired("chapter")
+	readCmd.Flags().Int("width", 0, "Width of image viewer (0 to fit the terminal)")
+	readCmd.Flags().Int("height", 0, "Height of image viewer (0 to fit the terminal)")
+	readCmd.Flags().Bool("continue", false, "Continue where you left off in --title")
+	readCmd.Flags().Bool("download", false, "Download the next or previous chapter when it is not in the library")
+	readCmd.Flags().Bool("stream", false, "Read --chapter straight from MangaDex without downloading it")
+	readCmd.Flags().Bool("data-saver", false, "Use compressed data-saver pages when streaming or downloading")
+	readCmd.Flags().String("mode", "", "Reading mode: auto, page, webtoon or spread")
+	readCmd.Flags().String("direction", "", "Page order of spreads: rtl or ltr")
+	readCmd.Flags().String("fit", "", "Page fit: fit-screen, fit-width, fit-height or original")
 
 	AddSubCommand(readCmd)
 }
\ No newline at end of file
@@ -1,7 +1,11 @@
 package cmd
 
 import (
+	"context"
 	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
 
 	"github.com/spf13/cobra"
 )
@@ -38,3 +42,15 @@ func Execute() error {
 func AddSubCommand(cmd *cobra.Command) {
 	rootCmd.AddCommand(cmd)
 }
+
+// interruptContext is cancelled on the first SIGINT or SIGTERM so work in
+// progress can stop cleanly. A second signal falls through to the default
+// handler and terminates the process.
+func interruptContext(parent context.Context) (context.Context, context.CancelFunc) {
+	ctx, stop := signal.NotifyContext(parent, os.Interrupt, syscall.SIGTERM)
+	go func() {
+		<-ctx.Done()
+		stop()
+	}()
+	return ctx, stop
+}
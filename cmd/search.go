@@ -2,6 +2,7 @@ package cmd
 
 import (
 	"bufio"
+	"context"
 	"fmt"
 	"manga-cli/internals/api"
 	"manga-cli/internals/config"
@@ -10,6 +11,7 @@ import (
 	"manga-cli/internals/utils"
 	"os"
 	"path/filepath"
+	"slices"
 	"strconv"
 	"strings"
 
@@ -26,29 +28,6 @@ var searchCmd = &cobra.Command{
 			os.Exit(1)
 		}
 	
-		width, _ := cmd.Flags().GetInt("width")
-		height, _ := cmd.Flags().GetInt("height")
-	
-		if width == 0 {
-			if w, err := config.GetConfigOption("width"); err == nil {
-				if wInt, ok := w.(float64); ok {
-					width = int(wInt)
-				} else if wInt, ok := w.(int); ok {
-					width = wInt
-				}
-			}
-		}
-	
-		if height == 0 {
-			if h, err := config.GetConfigOption("height"); err == nil {
-				if hInt, ok := h.(float64); ok {
-					height = int(hInt)
-				} else if hInt, ok := h.(int); ok {
-					height = hInt
-				}
-			}
-		}
-	
 		basePathRaw, err := config.GetConfigOption("path")
 		if err != nil {
 			fmt.Println("Failed to get manga path from config:", err)
@@ -62,7 +41,7 @@ var searchCmd = &cobra.Command{
 	
 		fmt.Println("Searching for manga:", title)
 	
-		resp, err := api.GetMangaIDByTitle(title)
+		resp, err := api.GetMangaIDByTitle(cmd.Context(), title)
 		if err != nil {
 			fmt.Println("Error searching manga", err)
 			os.Exit(1)
@@ -85,27 +64,67 @@ var searchCmd = &cobra.Command{
 		}
 		fmt.Println()
 		fmt.Print(selectedManga.Attributes.Title["en"])
-		selectedChapter := ShowChaptersList(selectedManga.ID)
+		selectedChapter := ShowChaptersList(cmd.Context(), selectedManga.ID)
 		if selectedChapter == nil {
 			fmt.Println("No chapter selected, exiting.")
 			return
 		}
 	
 		chapterStr := selectedChapter.Attributes.Chapter
+		mangaTitle := selectedManga.Attributes.Title["en"]
+
+		opts, err := readingOptions(cmd)
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+		if opts.Mode == readerUtil.ModeAuto {
+			opts.Mode = readerUtil.ModePage
+			if slices.Contains(selectedManga.TagNames(), api.TagLongStrip) {
+				opts.Mode = readerUtil.ModeWebtoon
+			}
+		}
+
+		dataSaver, _ := cmd.Flags().GetBool("data-saver")
+		if stream, _ := cmd.Flags().GetBool("stream"); stream {
+			ctx, stop := interruptContext(cmd.Context())
+			defer stop()
+			opts.Context = ctx
+			opts.DataSaver = dataSaver
+			opts.Fetch = fetchAdjacent(ctx, true, dataSaver)
+			if err := readerUtil.Read(readerUtil.Chapter{Title: mangaTitle, Number: chapterStr, ID: selectedChapter.ID}, opts); err != nil {
+				fmt.Println("Failed to start reader:", err)
+				os.Exit(1)
+			}
+			return
+		}
 	
 		folderPath := filepath.Join(basePath, selectedManga.Attributes.Title["en"], chapterStr)
 	
 		if fi, err := os.Stat(folderPath); err == nil && fi.IsDir() {
 			fmt.Printf("Chapter %s already downloaded, skipping download.\n", chapterStr)
 		} else {
-			err = downloader.DownloadChapter(selectedManga.Attributes.Title["en"], selectedChapter.ID, chapterStr, false)
+			if err := applyRateLimit(cmd); err != nil {
+				fmt.Println(err)
+				os.Exit(1)
+			}
+			ctx, stop := interruptContext(cmd.Context())
+			closeProgress := useProgress(1)
+			err = downloader.DownloadChapter(ctx, selectedManga.Attributes.Title["en"], selectedChapter.ID, chapterStr, false)
+			closeProgress()
+			stop()
 			if err != nil {
 				fmt.Println(err)
 				os.Exit(1)
 			}
 		}
 	
-		if err := readerUtil.StartReader(folderPath, width, height); err != nil {
+		ctx, stop := interruptContext(cmd.Context())
+		defer stop()
+		if download, _ := cmd.Flags().GetBool("download"); download {
+			opts.Fetch = fetchAdjacent(ctx, false, dataSaver)
+		}
+		if err := readerUtil.Open(folderPath, opts); err != nil {
 			fmt.Println("Failed to start reader:", err)
 			os.Exit(1)
 		}
@@ -116,6 +135,15 @@ var searchCmd = &cobra.Command{
 func init(){
 	searchCmd.Flags().StringVarP(&title, "title", "t", "", "Manga title (required)")
 
+	searchCmd.Flags().Int("width", 0, "Width of image viewer (0 to fit the terminal)")
+	searchCmd.Flags().Int("height", 0, "Height of image viewer (0 to fit the terminal)")
+	searchCmd.Flags().Bool("stream", false, "Read the chosen chapter straight from MangaDex without downloading it")
+	searchCmd.Flags().Bool("download", false, "Download the next or previous chapter when it is not in the library")
+	searchCmd.Flags().Bool("data-saver", false, "Use compressed data-saver pages when streaming or downloading")
+	searchCmd.Flags().String("mode", "", "Reading mode: auto, page, webtoon or spread")
+	searchCmd.Flags().String("direction", "", "Page order of spreads: rtl or ltr")
+	searchCmd.Flags().String("fit", "", "Page fit: fit-screen, fit-width, fit-height or original")
+
 	searchCmd.MarkFlagRequired("title")
 
 	AddSubCommand(searchCmd)
@@ -153,7 +181,7 @@ func selectManga(mangaList []api.MangaData) *api.MangaData{
 	}
 }
 
-func ShowChaptersList(selectedManga string) *api.ChapterData {
+func ShowChaptersList(ctx context.Context, selectedManga string) *api.ChapterData {
 	reader := bufio.NewReader(os.Stdin)
 	limit := 10
 	offset := 0
@@ -162,7 +190,7 @@ func ShowChaptersList(selectedManga string) *api.ChapterData {
 	for {
 		utils.ClearTerminal()
 
-		listChapters, err := api.GetChapterList(selectedManga, limit, offset)
+		listChapters, err := api.GetChapterList(ctx, selectedManga, limit, offset)
 		if err != nil {
 			fmt.Println("Error occurred fetching chapters:", err)
 			return nil
@@ -196,7 +224,7 @@ func ShowChaptersList(selectedManga string) *api.ChapterData {
 			}
 
 			if allChapters == nil {
-				allChapters, err = api.FetchAllChapters(selectedManga)
+				allChapters, err = api.FetchAllChapters(ctx, selectedManga)
 				if err != nil {
 					fmt.Println("Error fetching all chapters for search:", err)
 					reader.ReadString('\n')
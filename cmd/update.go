@@ -0,0 +1,224 @@
+package cmd
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"manga-cli/internals/config"
+	"manga-cli/internals/downloader"
+	"manga-cli/internals/follows"
+	"manga-cli/internals/library"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+var updateCmd = &cobra.Command{
+	Use:   "update",
+	Short: "Check tracked manga for new chapters and optionally download them",
+	Run: func(cmd *cobra.Command, args []string) {
+		updateTitle, _ := cmd.Flags().GetString("title")
+		dryRun, _ := cmd.Flags().GetBool("dry-run")
+		yes, _ := cmd.Flags().GetBool("yes")
+		disable, _ := cmd.Flags().GetBool("disable")
+		enable, _ := cmd.Flags().GetBool("enable")
+		dataSaver, _ := cmd.Flags().GetBool("data-saver")
+
+		if disable || enable {
+			if updateTitle == "" {
+				fmt.Println("Please specify --title with --enable or --disable")
+				os.Exit(1)
+			}
+			t := library.ResolveTitle(updateTitle)
+			if err := library.SetSkipUpdates(t, disable); err != nil {
+				fmt.Println("Failed to change update setting:", err)
+				os.Exit(1)
+			}
+			if disable {
+				fmt.Printf("Updates disabled for '%s'\n", t)
+			} else {
+				fmt.Printf("Updates enabled for '%s'\n", t)
+			}
+			return
+		}
+
+		language, groups := updatePreferences()
+
+		if err := applyRateLimit(cmd); err != nil {
+			fmt.Println("Error:", err)
+			os.Exit(1)
+		}
+
+		ctx, stop := interruptContext(cmd.Context())
+		defer stop()
+
+		updates, err := collectUpdates(ctx, updateTitle, language, groups)
+		if err != nil {
+			fmt.Println("Failed to check for updates:", err)
+			os.Exit(1)
+		}
+
+		total := 0
+		for _, u := range updates {
+			if len(u.Chapters) == 0 {
+				continue
+			}
+			total += len(u.Chapters)
+			fmt.Printf("📚 %s: %d new chapter(s)\n", u.Title, len(u.Chapters))
+			for _, ch := range u.Chapters {
+				group := ch.GroupName()
+				if group == "" {
+					group = "unknown group"
+				}
+				fmt.Printf("   #%-8s %-40s [%s]\n", ch.Attributes.Chapter, ch.Attributes.Title, group)
+			}
+		}
+
+		if total == 0 {
+			fmt.Println("Everything is up to date.")
+			return
+		}
+
+		if dryRun {
+			fmt.Printf("\n%d new chapter(s) available (dry run, nothing downloaded).\n", total)
+			return
+		}
+
+		if !yes && !confirm(fmt.Sprintf("\nDownload %d new chapter(s)? [y/N]: ", total)) {
+			return
+		}
+
+		closeProgress := useProgress(total)
+		defer closeProgress()
+
+		done := 0
+		for _, u := range updates {
+			for _, ch := range u.Chapters {
+				err := downloader.DownloadChapter(ctx, u.Title, ch.ID, ch.Attributes.Chapter, dataSaver)
+				if downloader.IsCancelled(err) {
+					closeProgress()
+					fmt.Printf("\nInterrupted: %d of %d new chapter(s) downloaded.\n", done, total)
+					os.Exit(1)
+				}
+				if err != nil {
+					downloader.Reporter().Logf("Error downloading %s chapter %s: %v", u.Title, ch.Attributes.Chapter, err)
+				} else {
+					done++
+				}
+			}
+		}
+	},
+}
+
+func init() {
+	updateCmd.Flags().String("title", "", "Only check this manga")
+	updateCmd.Flags().Bool("dry-run", false, "Show new chapters without downloading")
+	updateCmd.Flags().BoolP("yes", "y", false, "Download new chapters without asking")
+	updateCmd.Flags().Bool("disable", false, "Stop checking --title for updates")
+	updateCmd.Flags().Bool("enable", false, "Resume checking --title for updates")
+	updateCmd.Flags().Bool("data-saver", false, "Use data-saver mode for lower quality images")
+	updateCmd.Flags().String("limit-rate", "", "Maximum download speed, e.g. 500K or 2M")
+	AddSubCommand(updateCmd)
+}
+
+func updatePreferences() (string, []string) {
+	language := "en"
+	if val, err := config.GetConfigOption("language"); err == nil {
+		if s := fmt.Sprintf("%v", val); s != "" {
+			language = s
+		}
+	}
+
+	var groups []string
+	if val, err := config.GetConfigOption("groups"); err == nil {
+		for _, g := range strings.Split(fmt.Sprintf("%v", val), ",") {
+			if g = strings.TrimSpace(g); g != "" {
+				groups = append(groups, g)
+			}
+		}
+	}
+	return language, groups
+}
+
+func collectUpdates(ctx context.Context, updateTitle, language string, groups []string) ([]*library.Update, error) {
+	if updateTitle != "" {
+		u, err := library.CheckUpdates(ctx, library.ResolveTitle(updateTitle), language, groups)
+		if os.IsNotExist(err) {
+			// Not downloaded yet, but it may be followed.
+			u, err = checkFollowed(ctx, updateTitle, language, groups)
+		}
+		if err != nil {
+			return nil, err
+		}
+		return []*library.Update{u}, nil
+	}
+
+	idx, err := library.LoadIndex()
+	if err != nil {
+		return nil, err
+	}
+
+	var updates []*library.Update
+	for _, e := range idx.Entries() {
+		if e.SkipUpdates {
+			continue
+		}
+		fmt.Printf("Checking %s...\n", e.Title)
+		u, err := library.CheckUpdates(ctx, e.Title, language, groups)
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		if err != nil {
+			fmt.Printf("Skipping %s: %v\n", e.Title, err)
+			continue
+		}
+		updates = append(updates, u)
+	}
+
+	followed, err := follows.Load()
+	if err != nil {
+		return nil, err
+	}
+	for _, f := range followed {
+		if idx.FindByMangaID(f.MangaID) != nil {
+			continue
+		}
+		fmt.Printf("Checking %s...\n", f.Title)
+		after, _ := library.ChapterNumber(f.Baseline)
+		u, err := library.CheckUpdatesByID(ctx, f.Title, f.MangaID, after, language, groups)
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		if err != nil {
+			fmt.Printf("Skipping %s: %v\n", f.Title, err)
+			continue
+		}
+		updates = append(updates, u)
+	}
+	return updates, nil
+}
+
+// checkFollowed checks a followed manga that has no chapters in the library
+// for chapters newer than its baseline.
+func checkFollowed(ctx context.Context, title, language string, groups []string) (*library.Update, error) {
+	f, err := follows.Find(title)
+	if err != nil {
+		return nil, err
+	}
+	if f == nil {
+		return nil, fmt.Errorf("'%s' is neither in the library nor followed", title)
+	}
+	after, _ := library.ChapterNumber(f.Baseline)
+	return library.CheckUpdatesByID(ctx, f.Title, f.MangaID, after, language, groups)
+}
+
+func confirm(prompt string) bool {
+	fmt.Print(prompt)
+	input, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	if err != nil {
+		return false
+	}
+	input = strings.ToLower(strings.TrimSpace(input))
+	return input == "y" || input == "yes"
+}
@@ -0,0 +1,64 @@
+package cmd
+
+import (
+	"fmt"
+	"manga-cli/internals/library"
+	"os"
+
+	"github.com/spf13/cobra"
+)
+
+var verifyCmd = &cobra.Command{
+	Use:   "verify",
+	Short: "Check downloaded pages against the library manifest",
+	Run: func(cmd *cobra.Command, args []string) {
+		verifyTitle, _ := cmd.Flags().GetString("title")
+
+		var titles []string
+		if verifyTitle != "" {
+			titles = append(titles, library.ResolveTitle(verifyTitle))
+		} else {
+			idx, err := library.LoadIndex()
+			if err != nil {
+				fmt.Println("Failed to load library index:", err)
+				os.Exit(1)
+			}
+			for _, e := range idx.Entries() {
+				titles = append(titles, e.Title)
+			}
+		}
+
+		if len(titles) == 0 {
+			fmt.Println("Library is empty, nothing to verify.")
+			return
+		}
+
+		failed := false
+		for _, t := range titles {
+			problems, err := library.Verify(t)
+			if err != nil {
+				fmt.Printf("❌ %s: %v\n", t, err)
+				failed = true
+				continue
+			}
+			if len(problems) == 0 {
+				fmt.Printf("✅ %s\n", t)
+				continue
+			}
+			failed = true
+			fmt.Printf("❌ %s: %d problem(s)\n", t, len(problems))
+			for _, p := range problems {
+				fmt.Println("   ", p)
+			}
+		}
+
+		if failed {
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	verifyCmd.Flags().String("title", "", "Only verify this manga")
+	AddSubCommand(verifyCmd)
+}
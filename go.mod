@@ -4,6 +4,10 @@ go 1.24.1
 
 require (
 	github.com/inconshreveable/mousetrap v1.1.0 // indirect
+	github.com/nwaples/rardecode/v2 v2.1.1
 	github.com/spf13/cobra v1.9.1 // direct
 	github.com/spf13/pflag v1.0.6 // indirect
+	golang.org/x/image v0.30.0
+	golang.org/x/sys v0.35.0 // indirect
+	golang.org/x/term v0.34.0
 )
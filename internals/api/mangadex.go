@@ -1,6 +1,7 @@
 package api
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -12,6 +13,14 @@ import (
 
 const baseURL = "https://api.mangadex.org"
 
+func httpGet(ctx context.Context, url string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	return http.DefaultClient.Do(req)
+}
+
 type MangaSearchResult struct {
 	Data []MangaData `json:"data"`
 }
@@ -20,9 +29,40 @@ type MangaData struct {
 		ID         string `json:"id"`
 		Attributes struct {
 			Title map[string]string `json:"title"`
+			Tags  []Tag             `json:"tags"`
 		} `json:"attributes"`
 } 
 
+// TagLongStrip marks vertically scrolling webtoon-style series.
+const TagLongStrip = "Long Strip"
+
+type Tag struct {
+	ID         string `json:"id"`
+	Attributes struct {
+		Name  map[string]string `json:"name"`
+		Group string            `json:"group"`
+	} `json:"attributes"`
+}
+
+type MangaResponse struct {
+	Data MangaData `json:"data"`
+}
+
+// TagNames returns the English names of tags.
+func TagNames(tags []Tag) []string {
+	var names []string
+	for _, t := range tags {
+		if name := t.Attributes.Name["en"]; name != "" {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+func (m MangaData) TagNames() []string {
+	return TagNames(m.Attributes.Tags)
+}
+
 
 type ChapterSearchResult struct {
 	Data []ChapterData `json:"data"`
@@ -39,17 +79,117 @@ type ChapterData struct {
 		Chapter            string `json:"chapter"`
 		Title              string `json:"title"`
 		TranslatedLanguage string `json:"translatedLanguage"`
+		Pages              int    `json:"pages"`
 	} `json:"attributes"`
-	
+	Relationships []Relationship `json:"relationships"`
+}
+
+type Relationship struct {
+	ID         string          `json:"id"`
+	Type       string          `json:"type"`
+	Attributes json.RawMessage `json:"attributes,omitempty"`
+}
+
+type ChapterResponse struct {
+	Data ChapterData `json:"data"`
+}
+
+type relatedManga struct {
+	Title     map[string]string   `json:"title"`
+	AltTitles []map[string]string `json:"altTitles"`
+	Tags      []Tag               `json:"tags"`
+}
+
+type relatedGroup struct {
+	Name string `json:"name"`
+}
+
+func (c ChapterData) MangaID() string {
+	for _, rel := range c.Relationships {
+		if rel.Type == "manga" {
+			return rel.ID
+		}
+	}
+	return ""
+}
+
+func (c ChapterData) GroupName() string {
+	for _, rel := range c.Relationships {
+		if rel.Type != "scanlation_group" || len(rel.Attributes) == 0 {
+			continue
+		}
+		var group relatedGroup
+		if err := json.Unmarshal(rel.Attributes, &group); err == nil && group.Name != "" {
+			return group.Name
+		}
+	}
+	return ""
+}
+
+func (c ChapterData) MangaTitles() map[string]string {
+	titles := map[string]string{}
+	for _, rel := range c.Relationships {
+		if rel.Type != "manga" || len(rel.Attributes) == 0 {
+			continue
+		}
+		var manga relatedManga
+		if err := json.Unmarshal(rel.Attributes, &manga); err != nil {
+			continue
+		}
+		for lang, t := range manga.Title {
+			titles[lang] = t
+		}
+		for _, alt := range manga.AltTitles {
+			for lang, t := range alt {
+				if _, ok := titles[lang]; !ok {
+					titles[lang] = t
+				}
+			}
+		}
+	}
+	return titles
+}
+
+// MangaTags returns the English tag names of the manga a chapter belongs
+// to, when the chapter was fetched with the manga included.
+func (c ChapterData) MangaTags() []string {
+	for _, rel := range c.Relationships {
+		if rel.Type != "manga" || len(rel.Attributes) == 0 {
+			continue
+		}
+		var manga relatedManga
+		if err := json.Unmarshal(rel.Attributes, &manga); err == nil {
+			return TagNames(manga.Tags)
+		}
+	}
+	return nil
+}
+
+func GetManga(ctx context.Context, mangaID string) (*MangaData, error) {
+	resp, err := httpGet(ctx, fmt.Sprintf("%s/manga/%s", baseURL, mangaID))
+	if err != nil {
+		return nil, fmt.Errorf("HTTP request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected HTTP status: %s", resp.Status)
+	}
+
+	var result MangaResponse
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("failed to decode JSON: %w", err)
+	}
+	return &result.Data, nil
 }
 
-func GetMangaIDByTitle(title string) (MangaSearchResult, error) {
+func GetMangaIDByTitle(ctx context.Context, title string) (MangaSearchResult, error) {
 	endpoint := fmt.Sprintf("%s/manga", baseURL)
 
 	params := url.Values{}
 	params.Set("title", title)
 
-	resp, err := http.Get(endpoint + "?" + params.Encode())
+	resp, err := httpGet(ctx, endpoint+"?"+params.Encode())
 	if err != nil {
 		return MangaSearchResult{}, fmt.Errorf("HTTP request failed: %w", err)
 	}
@@ -77,11 +217,17 @@ func GetMangaIDByTitle(title string) (MangaSearchResult, error) {
 }
 
 
-func GetChapterList(mangaId string, limit int, offset int) (*ChapterSearchResult, error) {
+func GetChapterList(ctx context.Context, mangaId string, limit int, offset int) (*ChapterSearchResult, error) {
+	return GetChapterFeed(ctx, mangaId, "en", limit, offset)
+}
+
+func GetChapterFeed(ctx context.Context, mangaId string, language string, limit int, offset int) (*ChapterSearchResult, error) {
 	endpoint := fmt.Sprintf("%s/manga/%s/feed", baseURL, mangaId)
 
 	params := url.Values{}
-	params.Add("translatedLanguage[]", "en")
+	params.Add("translatedLanguage[]", language)
+	params.Add("includes[]", "scanlation_group")
+	params.Add("includeExternalUrl", "0")
 	params.Add("limit", strconv.Itoa(limit))
 	params.Add("offset", strconv.Itoa(offset))
 	params.Add("order[chapter]", "asc")
@@ -89,7 +235,7 @@ func GetChapterList(mangaId string, limit int, offset int) (*ChapterSearchResult
 
 	finalURL := fmt.Sprintf("%s?%s", endpoint, params.Encode())
 
-	resp, err := http.Get(finalURL)
+	resp, err := httpGet(ctx, finalURL)
 	if err != nil {
 		return &ChapterSearchResult{}, fmt.Errorf("HTTP request failed: %w", err)
 	}
@@ -113,13 +259,17 @@ func GetChapterList(mangaId string, limit int, offset int) (*ChapterSearchResult
 	return &result, nil
 }
 
-func FetchAllChapters(mangaID string) ([]*ChapterData, error) {
+func FetchAllChapters(ctx context.Context, mangaID string) ([]*ChapterData, error) {
+	return FetchAllChaptersInLanguage(ctx, mangaID, "en")
+}
+
+func FetchAllChaptersInLanguage(ctx context.Context, mangaID string, language string) ([]*ChapterData, error) {
 	var all []*ChapterData
 	limit := 100
 	offset := 0
 
 	for {
-		list, err := GetChapterList(mangaID, limit, offset)
+		list, err := GetChapterFeed(ctx, mangaID, language, limit, offset)
 		if err != nil {
 			return nil, err
 		}
@@ -137,6 +287,62 @@ func FetchAllChapters(mangaID string) ([]*ChapterData, error) {
 	return all, nil
 }
 
+func GetChapterByID(ctx context.Context, chapterID string) (*ChapterData, error) {
+	endpoint := fmt.Sprintf("%s/chapter/%s", baseURL, chapterID)
+
+	params := url.Values{}
+	params.Add("includes[]", "manga")
+	params.Add("includes[]", "scanlation_group")
+
+	resp, err := httpGet(ctx, endpoint+"?"+params.Encode())
+	if err != nil {
+		return nil, fmt.Errorf("HTTP request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected HTTP status: %s", resp.Status)
+	}
+
+	var result ChapterResponse
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("failed to decode JSON: %w", err)
+	}
+
+	return &result.Data, nil
+}
+
+func GetLatestChapter(ctx context.Context, mangaID string, language string) (*ChapterData, error) {
+	endpoint := fmt.Sprintf("%s/manga/%s/feed", baseURL, mangaID)
+
+	params := url.Values{}
+	params.Add("translatedLanguage[]", language)
+	params.Add("includeExternalUrl", "0")
+	params.Add("limit", "1")
+	params.Add("order[volume]", "desc")
+	params.Add("order[chapter]", "desc")
+
+	resp, err := httpGet(ctx, endpoint+"?"+params.Encode())
+	if err != nil {
+		return nil, fmt.Errorf("HTTP request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected HTTP status: %s", resp.Status)
+	}
+
+	var result ChapterSearchResult
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("failed to decode JSON: %w", err)
+	}
+
+	if len(result.Data) == 0 {
+		return nil, nil
+	}
+	return &result.Data[0], nil
+}
+
 type AtHomeResponse struct {
 	BaseURL string  `json:"baseUrl"`
 	Chapter Chapter `json:"chapter"`
@@ -148,10 +354,10 @@ type Chapter struct {
 	DataSaver []string `json:"dataSaver"`
 }
 
-func GetAtHomeServer(chapterID string) (*AtHomeResponse, error) {
+func GetAtHomeServer(ctx context.Context, chapterID string) (*AtHomeResponse, error) {
 	url := fmt.Sprintf("https://api.mangadex.org/at-home/server/%s", chapterID)
 
-	resp, err := http.Get(url)
+	resp, err := httpGet(ctx, url)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get at-home server: %w", err)
 	}
@@ -170,52 +376,75 @@ func GetAtHomeServer(chapterID string) (*AtHomeResponse, error) {
 }
 
 
-func GetChapterIDsByRange(title string, from, to int) (map[int]string, error) {
-	mangaResult, err := GetMangaIDByTitle(title)
+// chapterBatch is how many chapter numbers are asked for in one request, so
+// URLs stay short.
+const chapterBatch = 50
+
+// GetChapterIDs looks up the English chapters with the given numbers and
+// returns their IDs by number. Numbers without a chapter are left out.
+func GetChapterIDs(ctx context.Context, title string, numbers []int) (map[int]string, error) {
+	mangaResult, err := GetMangaIDByTitle(ctx, title)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get manga ID: %w", err)
 	}
 
-	query := url.Values{}
-	query.Set("manga", mangaResult.Data[0].ID)
-	query.Set("limit", "100")
-	query.Add("translatedLanguage[]", "en")
-
-	for i := from; i <= to; i++ {
-		query.Add("chapter[]", strconv.Itoa(i))
+	chapterMap := make(map[int]string)
+	for start := 0; start < len(numbers); start += chapterBatch {
+		batch := numbers[start:min(start+chapterBatch, len(numbers))]
+		if err := getChapterIDs(ctx, mangaResult.Data[0].ID, batch, chapterMap); err != nil {
+			return nil, err
+		}
 	}
+	return chapterMap, nil
+}
 
-	fullURL := fmt.Sprintf("https://api.mangadex.org/chapter?%s", query.Encode())
-
-	resp, err := http.Get(fullURL)
-	if err != nil {
-		return nil, fmt.Errorf("request failed: %w", err)
-	}
-	defer resp.Body.Close()
+// getChapterIDs adds the chapters with the given numbers to chapterMap,
+// paging through the results as there may be several per number.
+func getChapterIDs(ctx context.Context, mangaID string, numbers []int, chapterMap map[int]string) error {
+	limit := 100
+	for offset := 0; ; offset += limit {
+		query := url.Values{}
+		query.Set("manga", mangaID)
+		query.Set("limit", strconv.Itoa(limit))
+		query.Set("offset", strconv.Itoa(offset))
+		query.Add("translatedLanguage[]", "en")
+		for _, n := range numbers {
+			query.Add("chapter[]", strconv.Itoa(n))
+		}
 
-	if resp.StatusCode != 200 {
-		return nil, fmt.Errorf("bad status: %s", resp.Status)
-	}
+		fullURL := fmt.Sprintf("https://api.mangadex.org/chapter?%s", query.Encode())
 
-	var result ChapterSearchResult
-	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
-		return nil, fmt.Errorf("decode error: %w", err)
-	}
+		resp, err := httpGet(ctx, fullURL)
+		if err != nil {
+			return fmt.Errorf("request failed: %w", err)
+		}
 
-	chapterMap := make(map[int]string)
-	for _, ch := range result.Data {
-		num, err := strconv.Atoi(ch.Attributes.Chapter)
+		var result ChapterSearchResult
+		if resp.StatusCode != 200 {
+			err = fmt.Errorf("bad status: %s", resp.Status)
+		} else if err = json.NewDecoder(resp.Body).Decode(&result); err != nil {
+			err = fmt.Errorf("decode error: %w", err)
+		}
+		resp.Body.Close()
 		if err != nil {
-			continue 
+			return err
 		}
-		chapterMap[num] = ch.ID
-	}
 
-	return chapterMap, nil
+		for _, ch := range result.Data {
+			num, err := strconv.Atoi(ch.Attributes.Chapter)
+			if err != nil {
+				continue
+			}
+			chapterMap[num] = ch.ID
+		}
+		if len(result.Data) < limit {
+			return nil
+		}
+	}
 }
 
-func GetChapterIDByNumber(title string, chapterNumber int) (ChapterData, error) {
-	mangaResult, err := GetMangaIDByTitle(title)
+func GetChapterIDByNumber(ctx context.Context, title string, chapterNumber int) (ChapterData, error) {
+	mangaResult, err := GetMangaIDByTitle(ctx, title)
 	if err != nil {
 		return ChapterData{}, fmt.Errorf("failed to get manga ID: %w", err)
 	}
@@ -229,7 +458,7 @@ func GetChapterIDByNumber(title string, chapterNumber int) (ChapterData, error)
 
 	fullURL := fmt.Sprintf("%s?%s", endpoint, query.Encode())
 
-	resp, err := http.Get(fullURL)
+	resp, err := httpGet(ctx, fullURL)
 	if err != nil {
 		return ChapterData{}, fmt.Errorf("HTTP request failed: %w", err)
 	}
@@ -0,0 +1,238 @@
+package archive
+
+import (
+	"archive/zip"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/nwaples/rardecode/v2"
+)
+
+func IsArchive(path string) bool {
+	return isZip(path) || isRar(path)
+}
+
+func isZip(path string) bool {
+	ext := strings.ToLower(filepath.Ext(path))
+	return ext == ".cbz" || ext == ".zip"
+}
+
+func isRar(path string) bool {
+	ext := strings.ToLower(filepath.Ext(path))
+	return ext == ".cbr" || ext == ".rar"
+}
+
+// Walk calls fn for every regular file in a zip or rar archive. Names use
+// '/' as the separator, as stored in the archive.
+func Walk(path string, fn func(name string, r io.Reader) error) error {
+	switch {
+	case isZip(path):
+		return walkZip(path, fn)
+	case isRar(path):
+		return walkRar(path, fn)
+	}
+	return fmt.Errorf("unsupported archive: %s", path)
+}
+
+func walkZip(path string, fn func(name string, r io.Reader) error) error {
+	zr, err := zip.OpenReader(path)
+	if err != nil {
+		return err
+	}
+	defer zr.Close()
+
+	for _, f := range zr.File {
+		if f.FileInfo().IsDir() {
+			continue
+		}
+		rc, err := f.Open()
+		if err != nil {
+			return fmt.Errorf("%s: %w", f.Name, err)
+		}
+		err = fn(f.Name, rc)
+		rc.Close()
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func walkRar(path string, fn func(name string, r io.Reader) error) error {
+	rr, err := rardecode.OpenReader(path)
+	if err != nil {
+		return err
+	}
+	defer rr.Close()
+
+	for {
+		h, err := rr.Next()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if h.IsDir {
+			continue
+		}
+		if err := fn(h.Name, rr); err != nil {
+			return err
+		}
+	}
+}
+
+// Reader gives access to single entries of an archive. Zip entries are read
+// directly; rar archives can only be read front to back, so extracting one
+// entry also extracts every entry before it that has not been seen yet.
+type Reader struct {
+	path      string
+	zr        *zip.ReadCloser
+	files     map[string]*zip.File
+	names     []string
+	extracted map[string]string
+	rarPos    int
+}
+
+func Open(path string) (*Reader, error) {
+	r := &Reader{path: path, extracted: map[string]string{}}
+
+	switch {
+	case isZip(path):
+		zr, err := zip.OpenReader(path)
+		if err != nil {
+			return nil, err
+		}
+		r.zr = zr
+		r.files = map[string]*zip.File{}
+		for _, f := range zr.File {
+			if f.FileInfo().IsDir() {
+				continue
+			}
+			r.files[f.Name] = f
+			r.names = append(r.names, f.Name)
+		}
+	case isRar(path):
+		rr, err := rardecode.OpenReader(path)
+		if err != nil {
+			return nil, err
+		}
+		defer rr.Close()
+		for {
+			h, err := rr.Next()
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			if err != nil {
+				return nil, err
+			}
+			if !h.IsDir {
+				r.names = append(r.names, h.Name)
+			}
+		}
+	default:
+		return nil, fmt.Errorf("unsupported archive: %s", path)
+	}
+	return r, nil
+}
+
+// Names lists the files in the archive in stored order.
+func (r *Reader) Names() []string {
+	return r.names
+}
+
+// Extract writes one entry into dir and returns the path of the written
+// file. Entries that were extracted before are not written again.
+func (r *Reader) Extract(name, dir string) (string, error) {
+	if p, ok := r.extracted[name]; ok {
+		return p, nil
+	}
+
+	if r.zr != nil {
+		f, ok := r.files[name]
+		if !ok {
+			return "", fmt.Errorf("%s: not in archive", name)
+		}
+		rc, err := f.Open()
+		if err != nil {
+			return "", err
+		}
+		defer rc.Close()
+		return r.write(name, rc, dir)
+	}
+
+	rr, err := rardecode.OpenReader(r.path)
+	if err != nil {
+		return "", err
+	}
+	defer rr.Close()
+
+	pos := 0
+	for {
+		h, err := rr.Next()
+		if errors.Is(err, io.EOF) {
+			return "", fmt.Errorf("%s: not in archive", name)
+		}
+		if err != nil {
+			return "", err
+		}
+		if h.IsDir {
+			continue
+		}
+		pos++
+		if pos <= r.rarPos && h.Name != name {
+			continue
+		}
+		p, err := r.write(h.Name, rr, dir)
+		if err != nil {
+			return "", err
+		}
+		r.rarPos = max(r.rarPos, pos)
+		if h.Name == name {
+			return p, nil
+		}
+	}
+}
+
+func (r *Reader) write(name string, src io.Reader, dir string) (string, error) {
+	dst := filepath.Join(dir, strings.ReplaceAll(filepath.ToSlash(name), "/", "_"))
+	out, err := os.Create(dst)
+	if err != nil {
+		return "", err
+	}
+	_, err = io.Copy(out, src)
+	if closeErr := out.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(dst)
+		return "", err
+	}
+	r.extracted[name] = dst
+	return dst, nil
+}
+
+func (r *Reader) Close() error {
+	if r.zr != nil {
+		return r.zr.Close()
+	}
+	return nil
+}
+
+// Images returns the image entries of names in page order.
+func Images(names []string) []string {
+	var images []string
+	for _, name := range names {
+		switch strings.ToLower(filepath.Ext(name)) {
+		case ".jpg", ".jpeg", ".png", ".webp", ".gif":
+			images = append(images, name)
+		}
+	}
+	sort.Strings(images)
+	return images
+}
@@ -19,15 +19,31 @@ var ValidConfigOptions = map[string]struct {
 	Default     any
 }{
 	"path": {Description: "Path where downloaded manga is stored", Default: "~/Pictures/manga-cli"},
-	"viewer":        {Description: "External image viewer (e.g., viu, feh, imv, sxiv)", Default: "viu"},
+	"viewer":        {Description: "Image viewer profile (viu, chafa, timg, catimg, icat, feh, imv, sxiv, nsxiv), a command template like 'chafa --size {width}x{height} {path}', or builtin", Default: "viu"},
+	"viewers":       {Description: "Custom viewer profiles as JSON: {\"name\": {\"command\": \"...\", \"args\": [\"{path}\"], \"chapter\": false}}", Default: ""},
+	"keys":          {Description: "Reader key bindings as JSON, e.g. {\"next\": [\"n\", \"space\"], \"quit\": [\"q\"]}", Default: ""},
+	"mode":          {Description: "Reading mode: auto (webtoon for Long Strip titles), page, webtoon or spread", Default: "auto"},
+	"direction":     {Description: "Page order of spreads: rtl (manga) or ltr (comics)", Default: "rtl"},
+	"cover":         {Description: "Show the first page of a chapter alone in spread mode", Default: true},
+	"fit":           {Description: "How pages fit the screen: fit-screen, fit-width, fit-height or original", Default: "fit-screen"},
+	"prefetch":      {Description: "Pages the reader prepares ahead of and behind the current one (0 to disable)", Default: 2},
+	"protocol":      {Description: "Graphics protocol of the builtin viewer: auto, kitty, sixel, iterm or blocks", Default: "auto"},
 	"language":      {Description: "Preferred language for manga", Default: "en"},
+	"groups":        {Description: "Preferred scanlation groups, comma separated", Default: ""},
+	"limit_rate":    {Description: "Maximum download speed, e.g. 500K or 2M (empty for unlimited)", Default: ""},
+	"download_window": {Description: "Daily time window for queued downloads, e.g. 01:00-07:00", Default: ""},
+	"min_free_space":  {Description: "Free disk space to keep on the download path, e.g. 1G", Default: "1G"},
+	"low_space_action": {Description: "What to do when space runs low: warn or refuse", Default: "refuse"},
+	"quota":           {Description: "Maximum size of the library, e.g. 50G (empty for no quota)", Default: ""},
+	"eviction":        {Description: "How to make room under the quota: none, oldest or read-oldest", Default: "none"},
+	"process":         {Description: "Image transforms after download, e.g. format=jpeg,max-height=1600,grayscale,autocrop,split=rtl", Default: ""},
 	"width": {
-    	Description: "Default image width for terminal viewer",
-    	Default:     60,
+    	Description: "Default image width for terminal viewer (0 to fit the terminal)",
+    	Default:     0,
 	},
 	"height": {
-    	Description: "Default image height for terminal viewer",
-    	Default:     40,
+    	Description: "Default image height for terminal viewer (0 to fit the terminal)",
+    	Default:     0,
 	},
 
 }
@@ -86,6 +102,17 @@ func ensureConfigDir() error {
 	return os.MkdirAll(filepath.Join(home, configDirName), 0755)
 }
 
+func ConfigDir() (string, error) {
+	if err := ensureConfigDir(); err != nil {
+		return "", err
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, configDirName), nil
+}
+
 func LoadConfig() (Config, error) {
 	path := getConfigFilePath()
 
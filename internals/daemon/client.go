@@ -0,0 +1,49 @@
+package daemon
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"time"
+)
+
+const dialTimeout = 500 * time.Millisecond
+
+func dial() (net.Conn, error) {
+	path, err := SocketPath()
+	if err != nil {
+		return nil, err
+	}
+	return net.DialTimeout("unix", path, dialTimeout)
+}
+
+func Running() bool {
+	conn, err := dial()
+	if err != nil {
+		return false
+	}
+	conn.Close()
+	return true
+}
+
+func Call(req Request) (*Response, error) {
+	conn, err := dial()
+	if err != nil {
+		return nil, fmt.Errorf("daemon is not running: %w", err)
+	}
+	defer conn.Close()
+
+	if err := json.NewEncoder(conn).Encode(req); err != nil {
+		return nil, fmt.Errorf("failed to send request: %w", err)
+	}
+
+	var resp Response
+	if err := json.NewDecoder(conn).Decode(&resp); err != nil {
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+	if resp.Error != "" {
+		return &resp, errors.New(resp.Error)
+	}
+	return &resp, nil
+}
@@ -0,0 +1,9 @@
+//go:build windows
+
+package daemon
+
+import "syscall"
+
+func detachAttrs() *syscall.SysProcAttr {
+	return &syscall.SysProcAttr{CreationFlags: syscall.CREATE_NEW_PROCESS_GROUP}
+}
@@ -0,0 +1,63 @@
+package daemon
+
+import (
+	"manga-cli/internals/config"
+	"manga-cli/internals/queue"
+	"path/filepath"
+	"time"
+)
+
+const socketFileName = "daemon.sock"
+const logFileName = "daemon.log"
+
+const (
+	ActionStatus = "status"
+	ActionAdd    = "add"
+	ActionList   = "list"
+	ActionPause  = "pause"
+	ActionResume = "resume"
+	ActionRetry  = "retry"
+	ActionClear  = "clear"
+	ActionStop   = "stop"
+)
+
+type Request struct {
+	Action string      `json:"action"`
+	Jobs   []queue.Job `json:"jobs,omitempty"`
+	IDs    []int       `json:"ids,omitempty"`
+	All    bool        `json:"all,omitempty"`
+}
+
+type Response struct {
+	Error  string       `json:"error,omitempty"`
+	IDs    []int        `json:"ids,omitempty"`
+	Count  int          `json:"count"`
+	Queue  *queue.Queue `json:"queue,omitempty"`
+	Status *Status      `json:"status,omitempty"`
+}
+
+type Status struct {
+	PID       int        `json:"pid"`
+	StartedAt time.Time  `json:"startedAt"`
+	Current   *queue.Job `json:"current,omitempty"`
+	Pending   int        `json:"pending"`
+	Paused    int        `json:"paused"`
+	Failed    int        `json:"failed"`
+	Done      int        `json:"done"`
+}
+
+func SocketPath() (string, error) {
+	dir, err := config.ConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, socketFileName), nil
+}
+
+func LogPath() (string, error) {
+	dir, err := config.ConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, logFileName), nil
+}
@@ -0,0 +1,241 @@
+package daemon
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"manga-cli/internals/library"
+	"manga-cli/internals/queue"
+	"net"
+	"os"
+	"sync"
+	"time"
+)
+
+const pollInterval = 30 * time.Second
+
+type server struct {
+	listener  net.Listener
+	startedAt time.Time
+	wake      chan struct{}
+	ctx       context.Context
+	cancel    context.CancelFunc
+	stopOnce  sync.Once
+}
+
+// Serve runs the download queue and answers control requests on the daemon
+// socket until it is asked to stop or ctx is cancelled. A chapter that is
+// being downloaded at that point is put back in the queue.
+func Serve(ctx context.Context) error {
+	if Running() {
+		return fmt.Errorf("daemon is already running")
+	}
+
+	path, err := SocketPath()
+	if err != nil {
+		return err
+	}
+	_ = os.Remove(path)
+
+	listener, err := net.Listen("unix", path)
+	if err != nil {
+		return fmt.Errorf("failed to listen on %s: %w", path, err)
+	}
+	defer os.Remove(path)
+
+	if err := queue.Recover(); err != nil {
+		listener.Close()
+		return err
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	s := &server{
+		listener:  listener,
+		startedAt: time.Now(),
+		wake:      make(chan struct{}, 1),
+		ctx:       ctx,
+		cancel:    cancel,
+	}
+
+	go func() {
+		<-ctx.Done()
+		s.shutdown()
+	}()
+
+	done := make(chan struct{})
+	go func() {
+		s.work()
+		close(done)
+	}()
+
+	log.Printf("daemon listening on %s (pid %d)", path, os.Getpid())
+	s.accept()
+	<-done
+	log.Println("daemon stopped")
+	return nil
+}
+
+func (s *server) shutdown() {
+	s.stopOnce.Do(func() {
+		s.cancel()
+		s.listener.Close()
+	})
+}
+
+func (s *server) notify() {
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+}
+
+func (s *server) work() {
+	s.notify()
+	waiting := false
+	for {
+		select {
+		case <-s.ctx.Done():
+			return
+		case <-s.wake:
+		case <-time.After(pollInterval):
+		}
+
+		window, err := queue.ConfiguredWindow()
+		if err != nil {
+			log.Println("ignoring download window:", err)
+		}
+		if wait := window.Until(time.Now()); wait > 0 {
+			if !waiting {
+				log.Printf("outside download window %s, next run in %s", window, wait.Round(time.Minute))
+				waiting = true
+			}
+			select {
+			case <-s.ctx.Done():
+				return
+			case <-time.After(min(wait, pollInterval)):
+			}
+			s.notify()
+			continue
+		}
+		waiting = false
+
+		s.makeRoom()
+		results, err := queue.Process(s.ctx, window.InWindow, false)
+		if err != nil && s.ctx.Err() == nil {
+			log.Println("queue error:", err)
+		}
+		for _, r := range results {
+			if r.Err != nil {
+				log.Printf("failed %s chapter %s: %v", r.Job.Title, r.Job.Chapter, r.Err)
+			}
+		}
+	}
+}
+
+// makeRoom evicts chapters under the configured policy once before a run
+// of the pending jobs.
+func (s *server) makeRoom() {
+	q, err := queue.Load()
+	if err != nil {
+		log.Println("queue error:", err)
+		return
+	}
+	pending := q.Counts()[queue.StatusPending]
+	if pending == 0 {
+		return
+	}
+	removed, err := library.MakeRoom(pending, false)
+	for _, r := range removed {
+		log.Println("evicted", r)
+	}
+	if err != nil {
+		log.Println("failed to make room:", err)
+	}
+}
+
+func (s *server) accept() {
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			if s.ctx.Err() != nil {
+				return
+			}
+			log.Println("accept error:", err)
+			continue
+		}
+		go s.handle(conn)
+	}
+}
+
+func (s *server) handle(conn net.Conn) {
+	defer conn.Close()
+
+	var req Request
+	if err := json.NewDecoder(conn).Decode(&req); err != nil {
+		return
+	}
+
+	resp := s.dispatch(req)
+	_ = json.NewEncoder(conn).Encode(resp)
+}
+
+func (s *server) dispatch(req Request) Response {
+	var resp Response
+	var err error
+
+	switch req.Action {
+	case ActionStatus:
+		resp.Status, err = s.status()
+	case ActionAdd:
+		resp.IDs, err = queue.Add(req.Jobs)
+		resp.Count = len(resp.IDs)
+		s.notify()
+	case ActionList:
+		resp.Queue, err = queue.Load()
+	case ActionPause:
+		resp.Count, err = queue.Pause(req.IDs)
+	case ActionResume:
+		resp.Count, err = queue.Resume(req.IDs)
+		s.notify()
+	case ActionRetry:
+		resp.Count, err = queue.Retry(req.IDs)
+		s.notify()
+	case ActionClear:
+		resp.Count, err = queue.Clear(req.All)
+	case ActionStop:
+		s.shutdown()
+	default:
+		err = fmt.Errorf("unknown action '%s'", req.Action)
+	}
+
+	if err != nil {
+		resp.Error = err.Error()
+	}
+	return resp
+}
+
+func (s *server) status() (*Status, error) {
+	q, err := queue.Load()
+	if err != nil {
+		return nil, err
+	}
+
+	counts := q.Counts()
+	st := &Status{
+		PID:       os.Getpid(),
+		StartedAt: s.startedAt,
+		Pending:   counts[queue.StatusPending],
+		Paused:    counts[queue.StatusPaused],
+		Failed:    counts[queue.StatusFailed],
+		Done:      counts[queue.StatusDone],
+	}
+	for _, j := range q.Jobs {
+		if j.Status == queue.StatusRunning {
+			current := *j
+			st.Current = &current
+			break
+		}
+	}
+	return st, nil
+}
@@ -1,15 +1,34 @@
 package downloader
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"manga-cli/internals/api"
+	"manga-cli/internals/imageproc"
+	"manga-cli/internals/library"
+	"manga-cli/internals/progress"
 	"manga-cli/internals/utils"
 	"net/http"
 	"os"
 	"path/filepath"
+	"time"
 )
 
+const maxAttempts = 3
+
+var reporter progress.Reporter = progress.Plain(os.Stdout)
+
+func SetReporter(r progress.Reporter) {
+	reporter = r
+}
+
+func Reporter() progress.Reporter {
+	return reporter
+}
+
 type AtHomeResponse struct {
 	BaseURL string  `json:"baseUrl"`
 	Chapter Chapter `json:"chapter"`
@@ -21,35 +40,67 @@ type Chapter struct {
 	DataSaver []string `json:"dataSaver"`
 }
 
-func DownloadChapter(title string, chapterID string, chapterNo string, useDataSaver bool) error {
-	fmt.Printf(" Downloading chapter %s of \"%s\"...\n", chapterNo, title)
+func DownloadChapter(ctx context.Context, title string, chapterID string, chapterNo string, useDataSaver bool) error {
+	reporter.Logf(" Downloading chapter %s of \"%s\"...", chapterNo, title)
 
 	savePath, err := searchOrCreateFolder(title, chapterNo)
 	if err != nil {
 		return fmt.Errorf("failed to create folder: %w", err)
 	}
-	fmt.Printf(" Saving to: %s\n", savePath)
+	reporter.Logf(" Saving to: %s", savePath)
 
-	atHomeResp, err := getAtHomeServer(chapterID)
+	atHomeResp, err := getAtHomeServer(ctx, chapterID)
 	if err != nil {
+		removeIfEmpty(savePath)
 		return fmt.Errorf("failed to get at-home server info: %w", err)
 	}
 
-	fmt.Printf("Got server: %s\n", atHomeResp.BaseURL)
-	fmt.Printf(" Found %d pages to download.\n", len(atHomeResp.Chapter.Data))
 	if useDataSaver {
-		fmt.Println("Using Data Saver mode")
+		reporter.Logf(" Using Data Saver mode")
 	}
 
-	err = downloadChapterPages(atHomeResp, savePath, useDataSaver)
+	opts, err := imageproc.ParseOptions(library.ProcessingSpec(title))
 	if err != nil {
+		reporter.Logf(" Warning: ignoring image processing settings: %v", err)
+		opts = imageproc.Options{}
+	}
+
+	pages, err := downloadChapterPages(ctx, atHomeResp, savePath, useDataSaver, fmt.Sprintf("%s #%s", title, chapterNo), opts)
+	if err != nil {
+		removeIfEmpty(savePath)
 		return fmt.Errorf("failed to download pages: %w", err)
 	}
 
-	fmt.Println(" Chapter download complete.")
+	if err := recordChapter(ctx, title, chapterID, chapterNo, savePath, pages, useDataSaver); err != nil {
+		reporter.Logf(" Warning: failed to update library manifest: %v", err)
+	}
+
 	return nil
 }
 
+func recordChapter(ctx context.Context, title, chapterID, chapterNo, savePath string, pages []string, useDataSaver bool) error {
+	info := library.ChapterInfo{
+		ChapterID: chapterID,
+		Chapter:   chapterNo,
+		DataSaver: useDataSaver,
+	}
+
+	chData, err := api.GetChapterByID(ctx, chapterID)
+	if err == nil {
+		info.MangaID = chData.MangaID()
+		info.Titles = chData.MangaTitles()
+		info.Tags = chData.MangaTags()
+		info.Volume = chData.Attributes.Volume
+		info.Title = chData.Attributes.Title
+		info.Group = chData.GroupName()
+		info.Language = chData.Attributes.TranslatedLanguage
+	} else {
+		reporter.Logf(" Warning: could not fetch chapter metadata: %v", err)
+	}
+
+	return library.RecordChapter(title, info, savePath, pages)
+}
+
 
 func searchOrCreateFolder(title string, chapterNo string) (string, error) {
 	mangaCliDir, err := utils.GetOrCreateMangaCliDir()
@@ -66,10 +117,14 @@ func searchOrCreateFolder(title string, chapterNo string) (string, error) {
 	return savePath, nil
 }
 
-func getAtHomeServer(chapterID string) (*AtHomeResponse, error) {
+func getAtHomeServer(ctx context.Context, chapterID string) (*AtHomeResponse, error) {
 	url := fmt.Sprintf("https://api.mangadex.org/at-home/server/%s", chapterID)
 
-	resp, err := http.Get(url)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := http.DefaultClient.Do(req)
 	if err != nil {
 		return nil, fmt.Errorf("http get failed: %w", err)
 	}
@@ -86,63 +141,180 @@ func getAtHomeServer(chapterID string) (*AtHomeResponse, error) {
 
 	return &atHomeResp, nil
 }
-func downloadChapterPages(atHomeResp *AtHomeResponse, folderPath string, useDataSaver bool) error {
+func downloadChapterPages(ctx context.Context, atHomeResp *AtHomeResponse, folderPath string, useDataSaver bool, label string, opts imageproc.Options) (files []string, err error) {
 	pages := atHomeResp.Chapter.Data
 	if useDataSaver {
 		pages = atHomeResp.Chapter.DataSaver
 	}
 
 	var failedPages []string
-	totalPages := len(pages)
 
-	for i, page := range pages {
-		filePath := filepath.Join(folderPath, page)
-
-		progress := fmt.Sprintf("[%d/%d]", i+1, totalPages)
+	reporter.StartChapter(label, len(pages))
+	defer func() {
+		reporter.FinishChapter(err)
+	}()
 
-		if _, err := os.Stat(filePath); err == nil {
-			fmt.Printf("%s Skipped (exists): %s\n", progress, page)
-			continue
+	for _, page := range pages {
+		if err := ctx.Err(); err != nil {
+			return files, err
 		}
 
-		url := fmt.Sprintf("%s/data/%s/%s", atHomeResp.BaseURL, atHomeResp.Chapter.Hash, page)
+		filePath := filepath.Join(folderPath, page)
 
-		resp, err := http.Get(url)
-		if err != nil {
-			fmt.Printf("%s Failed to GET %s: %v\n", progress, page, err)
-			failedPages = append(failedPages, page)
+		if opts.Enabled() {
+			if existing := imageproc.Outputs(folderPath, page); len(existing) > 0 {
+				files = append(files, existing...)
+				reporter.PageDone(page, true)
+				continue
+			}
+		} else if _, err := os.Stat(filePath); err == nil {
+			files = append(files, page)
+			reporter.PageDone(page, true)
 			continue
 		}
-		defer resp.Body.Close()
 
-		if resp.StatusCode != http.StatusOK {
-			fmt.Printf("%s Bad status for %s: %s\n", progress, page, resp.Status)
+		url := PageURL(atHomeResp.BaseURL, atHomeResp.Chapter.Hash, page, useDataSaver)
+
+		if err := downloadPageWithRetry(ctx, url, filePath, page); err != nil {
+			if ctx.Err() != nil {
+				return files, ctx.Err()
+			}
+			reporter.PageFailed(page, err)
 			failedPages = append(failedPages, page)
 			continue
 		}
 
-		outFile, err := os.Create(filePath)
-		if err != nil {
-			fmt.Printf("%s Failed to create file %s: %v\n", progress, page, err)
-			failedPages = append(failedPages, page)
-			continue
+		outputs := []string{page}
+		if opts.Enabled() {
+			processed, err := imageproc.ProcessFile(filePath, opts)
+			if err != nil {
+				reporter.Logf("   ⚠ could not process %s: %v", page, err)
+			} else {
+				outputs = processed
+			}
 		}
+		files = append(files, outputs...)
+
+		reporter.PageDone(page, false)
+	}
 
-		_, err = io.Copy(outFile, resp.Body)
-		outFile.Close()
+	if len(failedPages) > 0 {
+		return files, fmt.Errorf("failed to download %d pages: %v", len(failedPages), failedPages)
+	}
 
-		if err != nil {
-			fmt.Printf("%s  Failed to write file %s: %v\n", progress, page, err)
-			failedPages = append(failedPages, page)
-			continue
+	return files, nil
+}
+
+func downloadPageWithRetry(ctx context.Context, url, filePath, page string) error {
+	return withRetry(ctx, func() error {
+		return downloadPage(ctx, url, filePath, true)
+	}, func(attempt int, err error) {
+		reporter.Retry(page, attempt, err)
+	})
+}
+
+// FetchPage downloads one page to filePath with the same retries and rate
+// limit as chapter downloads, but without reporting progress. The reader
+// uses it to stream chapters.
+func FetchPage(ctx context.Context, url, filePath string) error {
+	return withRetry(ctx, func() error {
+		return downloadPage(ctx, url, filePath, false)
+	}, func(int, error) {})
+}
+
+func withRetry(ctx context.Context, fn func() error, onRetry func(attempt int, err error)) error {
+	var err error
+	for attempt := 1; attempt <= maxAttempts; attempt++ {
+		err = fn()
+		if err == nil || ctx.Err() != nil || attempt == maxAttempts {
+			break
 		}
 
-		fmt.Printf("%s Downloaded: %s\n", progress, page)
+		onRetry(attempt+1, err)
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(time.Duration(attempt) * time.Second):
+		}
 	}
+	return err
+}
 
-	if len(failedPages) > 0 {
-		return fmt.Errorf("failed to download %d pages: %v", len(failedPages), failedPages)
+// downloadPage writes into a .part file next to filePath and only renames it
+// into place once the whole body has arrived, so an interrupted download never
+// leaves a truncated page behind.
+func downloadPage(ctx context.Context, url string, filePath string, report bool) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return err
 	}
 
-	return nil
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("GET failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("bad status: %s", resp.Status)
+	}
+
+	partPath := filePath + ".part"
+	outFile, err := os.Create(partPath)
+	if err != nil {
+		return fmt.Errorf("failed to create file: %w", err)
+	}
+
+	_, err = io.Copy(outFile, &countingReader{ctx: ctx, r: resp.Body, report: report})
+	if closeErr := outFile.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(partPath)
+		return fmt.Errorf("failed to write file: %w", err)
+	}
+
+	return os.Rename(partPath, filePath)
+}
+
+// PageURL builds the at-home URL of a page in full quality or data-saver
+// mode.
+func PageURL(baseURL, hash, page string, useDataSaver bool) string {
+	quality := "data"
+	if useDataSaver {
+		quality = "data-saver"
+	}
+	return fmt.Sprintf("%s/%s/%s/%s", baseURL, quality, hash, page)
+}
+
+func removeIfEmpty(dir string) {
+	entries, err := os.ReadDir(dir)
+	if err == nil && len(entries) == 0 {
+		os.Remove(dir)
+	}
+}
+
+func IsCancelled(err error) bool {
+	return errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded)
+}
+
+type countingReader struct {
+	ctx    context.Context
+	r      io.Reader
+	report bool
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	if n > 0 {
+		if c.report {
+			reporter.PageBytes(int64(n))
+		}
+		if l := rateLimit; l != nil {
+			if werr := l.wait(c.ctx, n); werr != nil {
+				return n, werr
+			}
+		}
+	}
+	return n, err
 }
@@ -0,0 +1,33 @@
+// Package filelock serialises updates of the JSON state files between
+// manga-cli processes, such as the CLI and the download daemon.
+package filelock
+
+import "os"
+
+// Lock takes an exclusive lock on path, creating the file if needed and
+// waiting while another process holds it. It returns the function that
+// releases the lock. The lock is not reentrant.
+func Lock(path string) (func(), error) {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_RDWR, 0644)
+	if err != nil {
+		return nil, err
+	}
+	if err := lockFile(f); err != nil {
+		f.Close()
+		return nil, err
+	}
+	return func() {
+		unlockFile(f)
+		f.Close()
+	}, nil
+}
+
+// WriteFile writes data to a temporary file next to path and renames it into
+// place, so readers never see a half written file.
+func WriteFile(path string, data []byte) error {
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
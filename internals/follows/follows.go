@@ -0,0 +1,116 @@
+package follows
+
+import (
+	"encoding/json"
+	"fmt"
+	"manga-cli/internals/config"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+const followsFileName = "follows.json"
+
+type Follow struct {
+	MangaID  string    `json:"mangaId"`
+	Title    string    `json:"title"`
+	Baseline string    `json:"baseline,omitempty"`
+	AddedAt  time.Time `json:"addedAt"`
+}
+
+func followsPath() (string, error) {
+	dir, err := config.ConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, followsFileName), nil
+}
+
+func Load() ([]Follow, error) {
+	path, err := followsPath()
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var list []Follow
+	if err := json.Unmarshal(data, &list); err != nil {
+		return nil, fmt.Errorf("failed to parse follows: %w", err)
+	}
+	return list, nil
+}
+
+func save(list []Follow) error {
+	path, err := followsPath()
+	if err != nil {
+		return err
+	}
+	sort.Slice(list, func(i, j int) bool {
+		return strings.ToLower(list[i].Title) < strings.ToLower(list[j].Title)
+	})
+	data, err := json.MarshalIndent(list, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0644)
+}
+
+func Add(f Follow) error {
+	list, err := Load()
+	if err != nil {
+		return err
+	}
+	for _, existing := range list {
+		if existing.MangaID == f.MangaID {
+			return fmt.Errorf("already following '%s'", existing.Title)
+		}
+	}
+	if f.AddedAt.IsZero() {
+		f.AddedAt = time.Now()
+	}
+	return save(append(list, f))
+}
+
+// Find returns the follow whose title (case-insensitive) or manga ID
+// matches, or nil when there is none.
+func Find(titleOrID string) (*Follow, error) {
+	list, err := Load()
+	if err != nil {
+		return nil, err
+	}
+	for _, f := range list {
+		if f.matches(titleOrID) {
+			return &f, nil
+		}
+	}
+	return nil, nil
+}
+
+func (f Follow) matches(titleOrID string) bool {
+	return f.MangaID == titleOrID || strings.EqualFold(f.Title, strings.TrimSpace(titleOrID))
+}
+
+// Remove drops the follow whose title (case-insensitive) or manga ID matches.
+func Remove(titleOrID string) (*Follow, error) {
+	list, err := Load()
+	if err != nil {
+		return nil, err
+	}
+
+	for i, f := range list {
+		if f.matches(titleOrID) {
+			list = append(list[:i], list[i+1:]...)
+			return &f, save(list)
+		}
+	}
+	return nil, fmt.Errorf("not following '%s'", titleOrID)
+}
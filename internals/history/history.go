@@ -0,0 +1,138 @@
+package history
+
+import (
+	"encoding/json"
+	"fmt"
+	"manga-cli/internals/config"
+	"manga-cli/internals/filelock"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+const historyFileName = "history.json"
+
+type ChapterState struct {
+	Page      int       `json:"page,omitempty"`
+	Completed bool      `json:"completed"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+type History map[string]map[string]*ChapterState
+
+// mu serialises history updates within this process; the lock file does the
+// same between the reader and other manga-cli processes.
+var mu sync.Mutex
+
+func historyPath() (string, error) {
+	dir, err := config.ConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, historyFileName), nil
+}
+
+func Load() (History, error) {
+	path, err := historyPath()
+	if err != nil {
+		return nil, err
+	}
+
+	h := History{}
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return h, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, &h); err != nil {
+		return nil, fmt.Errorf("failed to parse reading history: %w", err)
+	}
+	return h, nil
+}
+
+func (h History) Save() error {
+	path, err := historyPath()
+	if err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(h, "", "  ")
+	if err != nil {
+		return err
+	}
+	return filelock.WriteFile(path, data)
+}
+
+// update loads the history, applies fn and saves it while holding the
+// history lock, so concurrent readers do not overwrite each other.
+func update(fn func(h History)) error {
+	mu.Lock()
+	defer mu.Unlock()
+
+	path, err := historyPath()
+	if err != nil {
+		return err
+	}
+	unlock, err := filelock.Lock(path + ".lock")
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	h, err := Load()
+	if err != nil {
+		return err
+	}
+	fn(h)
+	return h.Save()
+}
+
+func (h History) Get(title, chapter string) *ChapterState {
+	if chapters, ok := h[title]; ok {
+		return chapters[chapter]
+	}
+	return nil
+}
+
+func (h History) set(title, chapter string, state *ChapterState) {
+	if h[title] == nil {
+		h[title] = map[string]*ChapterState{}
+	}
+	h[title][chapter] = state
+}
+
+// Last returns the chapter of a manga that was read most recently, or an
+// empty string when nothing has been read.
+func (h History) Last(title string) (string, *ChapterState) {
+	var last string
+	var state *ChapterState
+	for ch, st := range h[title] {
+		if state == nil || st.UpdatedAt.After(state.UpdatedAt) {
+			last, state = ch, st
+		}
+	}
+	return last, state
+}
+
+func MarkRead(title, chapter string) error {
+	return update(func(h History) {
+		page := 0
+		if st := h.Get(title, chapter); st != nil {
+			page = st.Page
+		}
+		h.set(title, chapter, &ChapterState{Page: page, Completed: true, UpdatedAt: time.Now()})
+	})
+}
+
+// SavePosition records the page (1-based) a chapter is open at. A chapter
+// stays completed once it has been read to the end.
+func SavePosition(title, chapter string, page int, completed bool) error {
+	return update(func(h History) {
+		if st := h.Get(title, chapter); st != nil && st.Completed {
+			completed = true
+		}
+		h.set(title, chapter, &ChapterState{Page: page, Completed: completed, UpdatedAt: time.Now()})
+	})
+}
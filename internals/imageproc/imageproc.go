@@ -0,0 +1,134 @@
+package imageproc
+
+import (
+	"fmt"
+	"image"
+	"image/jpeg"
+	"image/png"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	_ "image/gif"
+
+	_ "golang.org/x/image/webp"
+)
+
+func Decode(path string) (image.Image, string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, "", err
+	}
+	defer f.Close()
+	return image.Decode(f)
+}
+
+func Encode(path string, img image.Image, format string, quality int) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+
+	switch format {
+	case "jpeg":
+		err = jpeg.Encode(f, img, &jpeg.Options{Quality: quality})
+	default:
+		err = png.Encode(f, img)
+	}
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(path)
+	}
+	return err
+}
+
+func extension(format string) string {
+	if format == "jpeg" {
+		return ".jpg"
+	}
+	return "." + format
+}
+
+// ProcessFile applies opts to the page at path and returns the file names
+// (relative to the page's folder) that replace it, in reading order. The
+// original file is removed when it is not one of the outputs.
+func ProcessFile(path string, opts Options) ([]string, error) {
+	img, format, err := Decode(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode %s: %w", filepath.Base(path), err)
+	}
+
+	outFormat := opts.Format
+	if outFormat == "" {
+		outFormat = format
+	}
+	if outFormat != "jpeg" && outFormat != "png" {
+		// Go can decode WebP and GIF but not encode them.
+		outFormat = "png"
+	}
+
+	if opts.AutoCrop {
+		img = AutoCrop(img)
+	}
+
+	pages := []image.Image{img}
+	if opts.Split != "" && IsSpread(img) {
+		pages = SplitSpread(img, opts.Split)
+	}
+
+	dir := filepath.Dir(path)
+	stem := strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+
+	var outputs []string
+	for i, page := range pages {
+		page = ResizeToHeight(page, opts.MaxHeight)
+		if opts.Grayscale {
+			page = Grayscale(page)
+		}
+
+		name := stem + extension(outFormat)
+		if len(pages) > 1 {
+			name = fmt.Sprintf("%s_%d%s", stem, i+1, extension(outFormat))
+		}
+
+		if err := Encode(filepath.Join(dir, name+".part"), page, outFormat, opts.Quality); err != nil {
+			return nil, err
+		}
+		if err := os.Rename(filepath.Join(dir, name+".part"), filepath.Join(dir, name)); err != nil {
+			return nil, err
+		}
+		outputs = append(outputs, name)
+	}
+
+	original := filepath.Base(path)
+	kept := false
+	for _, o := range outputs {
+		if o == original {
+			kept = true
+		}
+	}
+	if !kept {
+		os.Remove(path)
+	}
+	return outputs, nil
+}
+
+// Outputs finds files already produced from page, so processed pages are
+// not downloaded again.
+func Outputs(dir, page string) []string {
+	stem := strings.TrimSuffix(page, filepath.Ext(page))
+	var found []string
+	for _, pattern := range []string{stem + ".*", stem + "_[0-9]*.*"} {
+		matches, _ := filepath.Glob(filepath.Join(dir, pattern))
+		for _, m := range matches {
+			if !strings.HasSuffix(m, ".part") {
+				found = append(found, filepath.Base(m))
+			}
+		}
+	}
+	sort.Strings(found)
+	return found
+}
@@ -0,0 +1,76 @@
+package imageproc
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+type Options struct {
+	Format    string
+	Quality   int
+	MaxHeight int
+	Grayscale bool
+	AutoCrop  bool
+	Split     string
+}
+
+const defaultQuality = 90
+
+// ParseOptions reads a comma separated transform spec such as
+// "format=jpeg,max-height=1600,grayscale,autocrop,split=rtl".
+func ParseOptions(spec string) (Options, error) {
+	opts := Options{Quality: defaultQuality}
+
+	for _, part := range strings.Split(spec, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		key, value, _ := strings.Cut(part, "=")
+		key = strings.ToLower(strings.TrimSpace(key))
+		value = strings.ToLower(strings.TrimSpace(value))
+
+		switch key {
+		case "format":
+			if value == "jpg" {
+				value = "jpeg"
+			}
+			if value != "jpeg" && value != "png" {
+				return opts, fmt.Errorf("unsupported output format '%s' (use jpeg or png)", value)
+			}
+			opts.Format = value
+		case "quality":
+			q, err := strconv.Atoi(value)
+			if err != nil || q < 1 || q > 100 {
+				return opts, fmt.Errorf("invalid jpeg quality '%s'", value)
+			}
+			opts.Quality = q
+		case "max-height":
+			h, err := strconv.Atoi(value)
+			if err != nil || h <= 0 {
+				return opts, fmt.Errorf("invalid max-height '%s'", value)
+			}
+			opts.MaxHeight = h
+		case "grayscale":
+			opts.Grayscale = true
+		case "autocrop":
+			opts.AutoCrop = true
+		case "split":
+			if value == "" {
+				value = "rtl"
+			}
+			if value != "rtl" && value != "ltr" {
+				return opts, fmt.Errorf("invalid split direction '%s' (use rtl or ltr)", value)
+			}
+			opts.Split = value
+		default:
+			return opts, fmt.Errorf("unknown transform '%s'", key)
+		}
+	}
+	return opts, nil
+}
+
+func (o Options) Enabled() bool {
+	return o.Format != "" || o.MaxHeight > 0 || o.Grayscale || o.AutoCrop || o.Split != ""
+}
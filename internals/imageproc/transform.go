@@ -0,0 +1,112 @@
+package imageproc
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+
+	xdraw "golang.org/x/image/draw"
+)
+
+const whiteThreshold = 235
+
+func Grayscale(img image.Image) image.Image {
+	b := img.Bounds()
+	gray := image.NewGray(b)
+	draw.Draw(gray, b, img, b.Min, draw.Src)
+	return gray
+}
+
+func Resize(img image.Image, width, height int) image.Image {
+	if width <= 0 || height <= 0 {
+		return img
+	}
+	dst := image.NewRGBA(image.Rect(0, 0, width, height))
+	xdraw.CatmullRom.Scale(dst, dst.Bounds(), img, img.Bounds(), xdraw.Src, nil)
+	return dst
+}
+
+func ResizeToHeight(img image.Image, maxHeight int) image.Image {
+	b := img.Bounds()
+	if maxHeight <= 0 || b.Dy() <= maxHeight {
+		return img
+	}
+	width := b.Dx() * maxHeight / b.Dy()
+	return Resize(img, max(1, width), maxHeight)
+}
+
+func Crop(img image.Image, r image.Rectangle) image.Image {
+	if sub, ok := img.(interface {
+		SubImage(image.Rectangle) image.Image
+	}); ok {
+		return sub.SubImage(r)
+	}
+	dst := image.NewRGBA(image.Rect(0, 0, r.Dx(), r.Dy()))
+	draw.Draw(dst, dst.Bounds(), img, r.Min, draw.Src)
+	return dst
+}
+
+func isWhite(c color.Color) bool {
+	g := color.GrayModel.Convert(c).(color.Gray)
+	return g.Y >= whiteThreshold
+}
+
+func rowIsWhite(img image.Image, y, x0, x1 int) bool {
+	for x := x0; x < x1; x++ {
+		if !isWhite(img.At(x, y)) {
+			return false
+		}
+	}
+	return true
+}
+
+func colIsWhite(img image.Image, x, y0, y1 int) bool {
+	for y := y0; y < y1; y++ {
+		if !isWhite(img.At(x, y)) {
+			return false
+		}
+	}
+	return true
+}
+
+// AutoCrop trims uniform white margins around the page.
+func AutoCrop(img image.Image) image.Image {
+	b := img.Bounds()
+	top, bottom, left, right := b.Min.Y, b.Max.Y, b.Min.X, b.Max.X
+
+	for top < bottom && rowIsWhite(img, top, left, right) {
+		top++
+	}
+	for bottom > top && rowIsWhite(img, bottom-1, left, right) {
+		bottom--
+	}
+	for left < right && colIsWhite(img, left, top, bottom) {
+		left++
+	}
+	for right > left && colIsWhite(img, right-1, top, bottom) {
+		right--
+	}
+
+	r := image.Rect(left, top, right, bottom)
+	if r.Dx() < b.Dx()/4 || r.Dy() < b.Dy()/4 {
+		return img
+	}
+	return Crop(img, r)
+}
+
+func IsSpread(img image.Image) bool {
+	b := img.Bounds()
+	return b.Dx()*10 > b.Dy()*11
+}
+
+// SplitSpread cuts a double-page spread into two pages in reading order.
+func SplitSpread(img image.Image, direction string) []image.Image {
+	b := img.Bounds()
+	mid := b.Min.X + b.Dx()/2
+	left := Crop(img, image.Rect(b.Min.X, b.Min.Y, mid, b.Max.Y))
+	right := Crop(img, image.Rect(mid, b.Min.Y, b.Max.X, b.Max.Y))
+	if direction == "ltr" {
+		return []image.Image{left, right}
+	}
+	return []image.Image{right, left}
+}
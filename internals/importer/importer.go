@@ -0,0 +1,390 @@
+package importer
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"manga-cli/internals/archive"
+	"manga-cli/internals/library"
+	"manga-cli/internals/utils"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+var ErrExists = errors.New("chapter already in library")
+
+// Item is one chapter found under an import path: either an archive or a
+// folder holding images directly.
+type Item struct {
+	Source   string
+	Title    string
+	Volume   string
+	Chapter  string
+	Name     string
+	Language string
+	Archive  bool
+}
+
+func (it Item) String() string {
+	chapter := it.Chapter
+	if chapter == "" {
+		chapter = "?"
+	}
+	s := fmt.Sprintf("%s #%s", it.Title, chapter)
+	if it.Volume != "" {
+		s = fmt.Sprintf("%s (vol. %s)", s, it.Volume)
+	}
+	return s
+}
+
+func isImageFile(name string) bool {
+	ext := strings.ToLower(filepath.Ext(name))
+	return ext == ".jpg" || ext == ".jpeg" || ext == ".png" || ext == ".webp" || ext == ".gif"
+}
+
+// Scan finds every importable chapter under path. A directory that contains
+// images is one chapter; archives are one chapter each; other directories
+// are searched recursively.
+func Scan(path string) ([]Item, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	if !info.IsDir() {
+		if !archive.IsArchive(path) {
+			return nil, fmt.Errorf("not an archive or folder: %s", path)
+		}
+		it, err := scanArchive(path)
+		if err != nil {
+			return nil, err
+		}
+		return []Item{it}, nil
+	}
+
+	var items []Item
+	var problems []string
+	err = filepath.WalkDir(path, func(p string, d os.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.IsDir() {
+			if archive.IsArchive(p) {
+				it, err := scanArchive(p)
+				if err != nil {
+					problems = append(problems, fmt.Sprintf("%s: %v", p, err))
+					return nil
+				}
+				items = append(items, it)
+			}
+			return nil
+		}
+
+		it, ok, err := scanFolder(p)
+		if err != nil {
+			problems = append(problems, fmt.Sprintf("%s: %v", p, err))
+			return nil
+		}
+		if ok {
+			items = append(items, it)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(problems) > 0 {
+		return items, fmt.Errorf("could not read %d source(s):\n  %s", len(problems), strings.Join(problems, "\n  "))
+	}
+	return items, nil
+}
+
+func scanArchive(path string) (Item, error) {
+	var info *ComicInfo
+	pages := 0
+	err := archive.Walk(path, func(name string, r io.Reader) error {
+		base := filepath.Base(name)
+		switch {
+		case strings.EqualFold(base, comicInfoName):
+			info, _ = parseComicInfo(r)
+		case isImageFile(base):
+			pages++
+		}
+		return nil
+	})
+	if err != nil {
+		return Item{}, err
+	}
+	if pages == 0 {
+		return Item{}, fmt.Errorf("no images in archive")
+	}
+
+	it := newItem(path, true)
+	it.apply(info)
+	return it, nil
+}
+
+func scanFolder(path string) (Item, bool, error) {
+	entries, err := os.ReadDir(path)
+	if err != nil {
+		return Item{}, false, err
+	}
+
+	hasImages := false
+	var info *ComicInfo
+	for _, e := range entries {
+		if e.IsDir() {
+			continue
+		}
+		if isImageFile(e.Name()) {
+			hasImages = true
+		}
+		if strings.EqualFold(e.Name(), comicInfoName) {
+			if f, err := os.Open(filepath.Join(path, e.Name())); err == nil {
+				info, _ = parseComicInfo(f)
+				f.Close()
+			}
+		}
+	}
+	if !hasImages {
+		return Item{}, false, nil
+	}
+
+	it := newItem(path, false)
+	it.apply(info)
+	return it, true, nil
+}
+
+// newItem fills title, volume and chapter from the source name, falling back
+// to the parent folder for the title when the name is only a number, as in
+// "One Piece/012". Volume-only sources such as "Berserk v01.cbz" get no
+// chapter, as volume numbers would clash with chapter numbers; Import
+// refuses them until a chapter is given.
+func newItem(path string, isArchive bool) Item {
+	title, volume, chapter := parseName(filepath.Base(path))
+	if title == "" {
+		title, _, _ = parseName(filepath.Base(filepath.Dir(path)))
+	}
+	return Item{Source: path, Title: title, Volume: volume, Chapter: chapter, Archive: isArchive}
+}
+
+func (it *Item) apply(info *ComicInfo) {
+	if info == nil {
+		return
+	}
+	if s := strings.TrimSpace(info.Series); s != "" {
+		it.Title = s
+	}
+	if n := strings.TrimSpace(info.Number); n != "" {
+		it.Chapter = normalizeNumber(n)
+	}
+	if v := strings.TrimSpace(info.Volume); v != "" {
+		it.Volume = normalizeNumber(v)
+	}
+	it.Name = strings.TrimSpace(info.Title)
+	it.Language = strings.TrimSpace(info.LanguageISO)
+}
+
+// Import copies the pages of an item into the library as
+// <title>/<chapter>/001.ext, 002.ext... in source order and records the
+// chapter in the manifest. Pages are staged in a temporary folder so a
+// failed import leaves nothing behind. With keepArchive an archive is copied
+// as <title>/<chapter>.cbz (or .cbr) instead and read in place.
+func Import(it Item, mangaID string, titles map[string]string, keepArchive bool) (int, error) {
+	if it.Chapter == "" && it.Volume != "" {
+		return 0, fmt.Errorf("name has volume %s but no chapter, give it with --chapter or name it like 'Title v01 c001'", it.Volume)
+	}
+	if it.Title == "" || it.Chapter == "" {
+		return 0, fmt.Errorf("cannot tell title and chapter of %s, use --title and name files like 'Title c012'", it.Source)
+	}
+	if err := checkNames(it); err != nil {
+		return 0, err
+	}
+
+	root, err := utils.GetOrCreateMangaCliDir()
+	if err != nil {
+		return 0, err
+	}
+	dir, err := library.MangaDir(it.Title)
+	if err != nil {
+		return 0, err
+	}
+	if !within(root, filepath.Join(dir, it.Chapter)) {
+		return 0, fmt.Errorf("%s would be imported outside the library", it.Source)
+	}
+	if existing, err := library.ChapterPath(it.Title, it.Chapter); err == nil {
+		if entries, err := os.ReadDir(existing); err != nil || len(entries) > 0 {
+			return 0, ErrExists
+		}
+	}
+
+	info := library.ChapterInfo{
+		MangaID:  mangaID,
+		Titles:   titles,
+		Chapter:  it.Chapter,
+		Volume:   it.Volume,
+		Title:    it.Name,
+		Language: it.Language,
+	}
+
+	if keepArchive && it.Archive {
+		return importArchive(it, dir, info)
+	}
+
+	dest := filepath.Join(dir, it.Chapter)
+	staging := dest + ".import"
+	os.RemoveAll(staging)
+	if err := os.MkdirAll(staging, 0755); err != nil {
+		return 0, err
+	}
+
+	pages, err := copyPages(it, staging)
+	if err != nil {
+		os.RemoveAll(staging)
+		return 0, err
+	}
+
+	os.Remove(dest)
+	if err := os.Rename(staging, dest); err != nil {
+		os.RemoveAll(staging)
+		return 0, err
+	}
+
+	if err := library.RecordChapter(it.Title, info, dest, pages); err != nil {
+		return len(pages), fmt.Errorf("imported but failed to update manifest: %w", err)
+	}
+	return len(pages), nil
+}
+
+// checkNames rejects titles and chapters that are not plain folder names,
+// such as a ComicInfo.xml series or number pointing elsewhere with "..".
+// Chapters must be numbers.
+func checkNames(it Item) error {
+	if it.Title == "." || strings.Contains(it.Title, "..") || strings.ContainsAny(it.Title, `/\`) {
+		return fmt.Errorf("invalid title '%s' for %s", it.Title, it.Source)
+	}
+	n, ok := library.ChapterNumber(it.Chapter)
+	if !ok || math.IsNaN(n) || math.IsInf(n, 0) {
+		return fmt.Errorf("invalid chapter number '%s' for %s", it.Chapter, it.Source)
+	}
+	return nil
+}
+
+// within reports whether path lies inside the folder root.
+func within(root, path string) bool {
+	rel, err := filepath.Rel(root, path)
+	if err != nil || rel == "." {
+		return false
+	}
+	return rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
+func importArchive(it Item, dir string, info library.ChapterInfo) (int, error) {
+	ar, err := archive.Open(it.Source)
+	if err != nil {
+		return 0, err
+	}
+	pages := len(archive.Images(ar.Names()))
+	ar.Close()
+
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return 0, err
+	}
+
+	os.Remove(filepath.Join(dir, it.Chapter))
+	name := it.Chapter + strings.ToLower(filepath.Ext(it.Source))
+	dest := filepath.Join(dir, name)
+	if err := copyFile(it.Source, dest); err != nil {
+		return 0, err
+	}
+
+	info.Archive = name
+	if err := library.RecordChapter(it.Title, info, dir, []string{name}); err != nil {
+		return pages, fmt.Errorf("imported but failed to update manifest: %w", err)
+	}
+	return pages, nil
+}
+
+func copyFile(src, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	partPath := dst + ".part"
+	out, err := os.Create(partPath)
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(out, in)
+	if closeErr := out.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(partPath)
+		return err
+	}
+	return os.Rename(partPath, dst)
+}
+
+func copyPages(it Item, staging string) ([]string, error) {
+	var names []string
+	write := func(name string, r io.Reader) error {
+		if !isImageFile(name) {
+			return nil
+		}
+		flat := "src-" + strings.ReplaceAll(filepath.ToSlash(name), "/", "_")
+		out, err := os.Create(filepath.Join(staging, flat))
+		if err != nil {
+			return err
+		}
+		_, err = io.Copy(out, r)
+		if closeErr := out.Close(); err == nil {
+			err = closeErr
+		}
+		if err != nil {
+			return err
+		}
+		names = append(names, flat)
+		return nil
+	}
+
+	if it.Archive {
+		if err := archive.Walk(it.Source, write); err != nil {
+			return nil, err
+		}
+	} else {
+		entries, err := os.ReadDir(it.Source)
+		if err != nil {
+			return nil, err
+		}
+		for _, e := range entries {
+			if e.IsDir() {
+				continue
+			}
+			f, err := os.Open(filepath.Join(it.Source, e.Name()))
+			if err != nil {
+				return nil, err
+			}
+			err = write(e.Name(), f)
+			f.Close()
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	sort.Strings(names)
+	pages := make([]string, 0, len(names))
+	for i, name := range names {
+		page := fmt.Sprintf("%03d%s", i+1, strings.ToLower(filepath.Ext(name)))
+		if err := os.Rename(filepath.Join(staging, name), filepath.Join(staging, page)); err != nil {
+			return nil, err
+		}
+		pages = append(pages, page)
+	}
+	return pages, nil
+}
@@ -0,0 +1,111 @@
+package importer
+
+import (
+	"manga-cli/internals/config"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestCheckNames(t *testing.T) {
+	tests := []struct {
+		title, chapter string
+		ok             bool
+	}{
+		{"One Piece", "12", true},
+		{"One Piece", "12.5", true},
+		{"Fate/stay night", "1", false},
+		{`C:\Windows`, "1", false},
+		{"..", "1", false},
+		{".", "1", false},
+		{"/../../x", "1", false},
+		{"One Piece", "../../../x", false},
+		{"One Piece", "12/../../x", false},
+		{"One Piece", "Extra", false},
+		{"One Piece", "NaN", false},
+		{"One Piece", "Inf", false},
+	}
+	for _, tt := range tests {
+		err := checkNames(Item{Source: "src", Title: tt.title, Chapter: tt.chapter})
+		if (err == nil) != tt.ok {
+			t.Errorf("checkNames(%q, %q) = %v, want ok %v", tt.title, tt.chapter, err, tt.ok)
+		}
+	}
+}
+
+func TestWithin(t *testing.T) {
+	root := filepath.FromSlash("/lib")
+	tests := map[string]bool{
+		"/lib/One Piece/12":  true,
+		"/lib/..x/1":         true,
+		"/lib":               false,
+		"/lib/../x":          false,
+		"/library/One Piece": false,
+		"/x/../lib/a/b":      true,
+	}
+	for path, want := range tests {
+		if got := within(root, filepath.FromSlash(path)); got != want {
+			t.Errorf("within(%q) = %v, want %v", path, got, want)
+		}
+	}
+}
+
+// setupLibrary points the config and library at a fresh folder and returns
+// the folder and a source folder holding one page.
+func setupLibrary(t *testing.T) (home, src string) {
+	home = t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("USERPROFILE", home)
+	if err := config.SetConfigOption("path", filepath.Join(home, "library")); err != nil {
+		t.Fatal(err)
+	}
+
+	src = filepath.Join(t.TempDir(), "source")
+	if err := os.MkdirAll(src, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(src, "01.png"), []byte("page"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return home, src
+}
+
+func TestImportRejectsEscapingNames(t *testing.T) {
+	home, src := setupLibrary(t)
+
+	items := []Item{
+		{Source: src, Title: "Evil", Chapter: "../../../../escape"},
+		{Source: src, Title: "../../escape", Chapter: "1"},
+	}
+	for _, it := range items {
+		if _, err := Import(it, "", nil, false); err == nil {
+			t.Errorf("Import(%q, %q) succeeded", it.Title, it.Chapter)
+		}
+		dest := filepath.Join(home, "library", it.Title, it.Chapter)
+		for _, p := range []string{dest, dest + ".import"} {
+			if _, err := os.Stat(p); !os.IsNotExist(err) {
+				t.Errorf("%s was created", p)
+			}
+		}
+	}
+}
+
+func TestImportFolder(t *testing.T) {
+	home, src := setupLibrary(t)
+
+	n, err := Import(Item{Source: src, Title: "One Piece", Chapter: "12"}, "", nil, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != 1 {
+		t.Errorf("imported %d pages, want 1", n)
+	}
+	page := filepath.Join(home, "library", "One Piece", "12", "001.png")
+	if _, err := os.Stat(page); err != nil {
+		t.Error(err)
+	}
+
+	if _, err := Import(Item{Source: src, Title: "One Piece", Chapter: "12"}, "", nil, false); err != ErrExists {
+		t.Errorf("second import returned %v, want ErrExists", err)
+	}
+}
@@ -0,0 +1,78 @@
+package importer
+
+import (
+	"encoding/xml"
+	"io"
+	"manga-cli/internals/archive"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+const comicInfoName = "comicinfo.xml"
+
+// ComicInfo holds the fields of a ComicRack ComicInfo.xml that matter for
+// placing a chapter in the library.
+type ComicInfo struct {
+	Series      string `xml:"Series"`
+	Number      string `xml:"Number"`
+	Volume      string `xml:"Volume"`
+	Title       string `xml:"Title"`
+	LanguageISO string `xml:"LanguageISO"`
+}
+
+func parseComicInfo(r io.Reader) (*ComicInfo, error) {
+	var info ComicInfo
+	if err := xml.NewDecoder(r).Decode(&info); err != nil {
+		return nil, err
+	}
+	return &info, nil
+}
+
+var (
+	bracketRe = regexp.MustCompile(`\[[^\]]*\]|\([^)]*\)|\{[^}]*\}`)
+	chapterRe = regexp.MustCompile(`(?i)(?:^|[\s._-])(?:c|ch|chap|chapter)[\s.#]*(\d+(?:\.\d+)?)`)
+	volumeRe  = regexp.MustCompile(`(?i)(?:^|[\s._-])(?:v|vol|volume)[\s.#]*(\d+(?:\.\d+)?)`)
+	numberRe  = regexp.MustCompile(`(\d+(?:\.\d+)?)\s*$`)
+)
+
+// parseName infers title, volume and chapter from a file or folder name such
+// as "One Piece v01 c003 [Group].cbz" or "One Piece - 012.5". The title is
+// empty when the name holds nothing but numbers.
+func parseName(name string) (title, volume, chapter string) {
+	if archive.IsArchive(name) {
+		name = strings.TrimSuffix(name, filepath.Ext(name))
+	}
+	name = bracketRe.ReplaceAllString(name, " ")
+	name = strings.ReplaceAll(name, "_", " ")
+
+	cut := len(name)
+	if m := chapterRe.FindStringSubmatchIndex(name); m != nil {
+		chapter = name[m[2]:m[3]]
+		cut = min(cut, m[0])
+	}
+	if m := volumeRe.FindStringSubmatchIndex(name); m != nil {
+		volume = name[m[2]:m[3]]
+		cut = min(cut, m[0])
+	}
+	if chapter == "" {
+		if m := numberRe.FindStringSubmatchIndex(name[:cut]); m != nil {
+			chapter = name[m[2]:m[3]]
+			cut = m[0]
+		}
+	}
+
+	title = strings.Trim(name[:cut], " .-#")
+	return title, normalizeNumber(volume), normalizeNumber(chapter)
+}
+
+// normalizeNumber turns "012" into "12" and "07.50" into "7.5" so imported
+// chapters share keys with downloaded ones.
+func normalizeNumber(s string) string {
+	n, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return s
+	}
+	return strconv.FormatFloat(n, 'f', -1, 64)
+}
@@ -0,0 +1,104 @@
+package importer
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseName(t *testing.T) {
+	tests := []struct {
+		name                   string
+		title, volume, chapter string
+	}{
+		{"One Piece v01 c003 [Group].cbz", "One Piece", "1", "3"},
+		{"One Piece - 012.5", "One Piece", "", "12.5"},
+		{"Berserk v01.cbz", "Berserk", "1", ""},
+		{"Vinland_Saga_ch_07.50.zip", "Vinland Saga", "", "7.5"},
+		{"Chapter 12 (2019)", "", "", "12"},
+		{"012", "", "", "12"},
+		{"One Piece - 012.5.cbz", "One Piece", "", "12.5"},
+		{"../../../x.cbz", "/../../x", "", ""},
+		{"..", "", "", ""},
+	}
+	for _, tt := range tests {
+		title, volume, chapter := parseName(tt.name)
+		if title != tt.title || volume != tt.volume || chapter != tt.chapter {
+			t.Errorf("parseName(%q) = %q, %q, %q, want %q, %q, %q",
+				tt.name, title, volume, chapter, tt.title, tt.volume, tt.chapter)
+		}
+	}
+}
+
+func TestNormalizeNumber(t *testing.T) {
+	tests := map[string]string{
+		"012":   "12",
+		"07.50": "7.5",
+		"1":     "1",
+		"Extra": "Extra",
+		"../x":  "../x",
+	}
+	for in, want := range tests {
+		if got := normalizeNumber(in); got != want {
+			t.Errorf("normalizeNumber(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestComicInfo(t *testing.T) {
+	xml := `<?xml version="1.0"?>
+<ComicInfo>
+  <Series> One Piece </Series>
+  <Number>0012</Number>
+  <Volume>02</Volume>
+  <Title>Romance Dawn</Title>
+  <LanguageISO>en</LanguageISO>
+</ComicInfo>`
+
+	info, err := parseComicInfo(strings.NewReader(xml))
+	if err != nil {
+		t.Fatal(err)
+	}
+	it := newItem("/imports/whatever c001.cbz", true)
+	it.apply(info)
+
+	want := Item{
+		Source:   "/imports/whatever c001.cbz",
+		Title:    "One Piece",
+		Volume:   "2",
+		Chapter:  "12",
+		Name:     "Romance Dawn",
+		Language: "en",
+		Archive:  true,
+	}
+	if it != want {
+		t.Errorf("got %+v, want %+v", it, want)
+	}
+}
+
+func TestComicInfoMissingFields(t *testing.T) {
+	info, err := parseComicInfo(strings.NewReader(`<ComicInfo><Series></Series></ComicInfo>`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	it := newItem("/imports/Berserk v03 c020.cbz", true)
+	it.apply(info)
+	if it.Title != "Berserk" || it.Volume != "3" || it.Chapter != "20" {
+		t.Errorf("file name values were overwritten: %+v", it)
+	}
+}
+
+func TestNewItemVolumeOnly(t *testing.T) {
+	it := newItem("/imports/Berserk v01.cbz", true)
+	if it.Title != "Berserk" || it.Volume != "1" || it.Chapter != "" {
+		t.Errorf("volume-only name should leave the chapter empty: %+v", it)
+	}
+	if _, err := Import(it, "", nil, false); err == nil {
+		t.Error("expected volume-only item to be refused")
+	}
+}
+
+func TestComicInfoInvalid(t *testing.T) {
+	if _, err := parseComicInfo(strings.NewReader("<ComicInfo><Series>")); err == nil {
+		t.Error("expected an error for truncated XML")
+	}
+}
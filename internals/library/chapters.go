@@ -0,0 +1,65 @@
+package library
+
+import (
+	"manga-cli/internals/archive"
+	"manga-cli/internals/utils"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+func ChapterNumber(chapter string) (float64, bool) {
+	n, err := strconv.ParseFloat(chapter, 64)
+	if err != nil {
+		return 0, false
+	}
+	return n, true
+}
+
+func SortChapters(chapters []string) {
+	sort.SliceStable(chapters, func(i, j int) bool {
+		a, aok := ChapterNumber(chapters[i])
+		b, bok := ChapterNumber(chapters[j])
+		switch {
+		case aok && bok:
+			return a < b
+		case aok != bok:
+			return aok
+		default:
+			return chapters[i] < chapters[j]
+		}
+	})
+}
+
+func (m *Manifest) ChapterKeys() []string {
+	keys := make([]string, 0, len(m.Chapters))
+	for k := range m.Chapters {
+		keys = append(keys, k)
+	}
+	SortChapters(keys)
+	return keys
+}
+
+// chapterName returns the chapter a library entry holds: the folder name, or
+// the file name without extension for a chapter kept as an archive.
+func chapterName(e os.DirEntry) (string, bool) {
+	if e.IsDir() {
+		return e.Name(), true
+	}
+	if archive.IsArchive(e.Name()) {
+		return strings.TrimSuffix(e.Name(), filepath.Ext(e.Name())), true
+	}
+	return "", false
+}
+
+// ChapterPath returns the folder of a downloaded chapter or, when the chapter
+// is kept as an archive, the archive file.
+func ChapterPath(title, chapter string) (string, error) {
+	dir, err := MangaDir(title)
+	if err != nil {
+		return "", err
+	}
+	return utils.FindChapterPath(dir, chapter)
+}
@@ -0,0 +1,179 @@
+package library
+
+import (
+	"context"
+	"fmt"
+	"manga-cli/internals/api"
+	"math"
+	"os"
+	"slices"
+)
+
+type GapReport struct {
+	Title       string
+	MangaID     string
+	Missing     []*api.ChapterData
+	Unavailable []int
+}
+
+// FindGaps compares the local chapter folders of a manga with its remote
+// feed. Missing lists remote chapters that are not downloaded, up to the
+// highest local chapter unless includeNewer is set. Unavailable lists whole
+// chapter numbers absent locally that the feed does not offer either.
+func FindGaps(ctx context.Context, title, language string, groups []string, includeNewer bool) (*GapReport, error) {
+	report := &GapReport{Title: title}
+
+	var removed []string
+	if m, err := LoadManifest(title); err == nil {
+		removed = m.Removed
+	}
+	mangaID, err := MangaIDFor(ctx, title)
+	if err != nil {
+		return nil, err
+	}
+	report.MangaID = mangaID
+
+	local, err := LocalChapters(title)
+	if err != nil {
+		return nil, err
+	}
+
+	have := map[float64]bool{}
+	highest := 0.0
+	for _, list := range [][]string{local, removed} {
+		for _, ch := range list {
+			if n, ok := ChapterNumber(ch); ok {
+				have[n] = true
+				highest = max(highest, n)
+			}
+		}
+	}
+
+	feed, err := api.FetchAllChaptersInLanguage(ctx, mangaID, language)
+	if err != nil {
+		return nil, err
+	}
+
+	remote := map[int]bool{}
+	for _, ch := range PickChapters(feed, groups) {
+		n, ok := ChapterNumber(ch.Attributes.Chapter)
+		if !ok {
+			continue
+		}
+		remote[int(math.Floor(n))] = true
+		if have[n] || (!includeNewer && n > highest) {
+			continue
+		}
+		report.Missing = append(report.Missing, ch)
+	}
+
+	for _, k := range MissingChapters(local, removed) {
+		if !remote[k] {
+			report.Unavailable = append(report.Unavailable, k)
+		}
+	}
+	return report, nil
+}
+
+// MangaIDFor returns the MangaDex ID recorded in the manifest of a manga, or
+// looks the title up when there is none.
+func MangaIDFor(ctx context.Context, title string) (string, error) {
+	if m, err := LoadManifest(title); err == nil && m.MangaID != "" {
+		return m.MangaID, nil
+	}
+	result, err := api.GetMangaIDByTitle(ctx, title)
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve '%s' on MangaDex: %w", title, err)
+	}
+	return result.Data[0].ID, nil
+}
+
+// IsLongStrip reports whether a manga is tagged "Long Strip" on MangaDex.
+// The tags are looked up once and kept in the manifest of downloaded manga.
+func IsLongStrip(ctx context.Context, title string) (bool, error) {
+	m, err := LoadManifest(title)
+	if err == nil && m.Tags != nil {
+		return slices.Contains(m.Tags, api.TagLongStrip), nil
+	}
+
+	mangaID, err := MangaIDFor(ctx, title)
+	if err != nil {
+		return false, err
+	}
+	manga, err := api.GetManga(ctx, mangaID)
+	if err != nil {
+		return false, err
+	}
+	tags := manga.TagNames()
+	if tags == nil {
+		// Remember that there are none so they are not looked up again.
+		tags = []string{}
+	}
+	if m != nil {
+		err := updateManifest(title, false, func(m *Manifest) error {
+			m.Tags = tags
+			return nil
+		})
+		if err != nil && !os.IsNotExist(err) {
+			return false, err
+		}
+	}
+	return slices.Contains(tags, api.TagLongStrip), nil
+}
+
+// AdjacentRemoteChapter finds the chapter on MangaDex that follows (or, with
+// forward unset, precedes) chapter. It returns nil when there is none.
+func AdjacentRemoteChapter(ctx context.Context, title, chapter string, forward bool, language string, groups []string) (*api.ChapterData, error) {
+	current, ok := ChapterNumber(chapter)
+	if !ok {
+		return nil, fmt.Errorf("chapter '%s' is not a number", chapter)
+	}
+	chapters, err := remoteChapters(ctx, title, language, groups)
+	if err != nil {
+		return nil, err
+	}
+
+	var best *api.ChapterData
+	bestN := 0.0
+	for _, ch := range chapters {
+		n, ok := ChapterNumber(ch.Attributes.Chapter)
+		if !ok || n == current || (n > current) != forward {
+			continue
+		}
+		if best == nil || (forward && n < bestN) || (!forward && n > bestN) {
+			best, bestN = ch, n
+		}
+	}
+	return best, nil
+}
+
+// RemoteChapter finds chapter of a manga on MangaDex in the given language,
+// preferring releases by groups.
+func RemoteChapter(ctx context.Context, title, chapter, language string, groups []string) (*api.ChapterData, error) {
+	want, ok := ChapterNumber(chapter)
+	if !ok {
+		return nil, fmt.Errorf("chapter '%s' is not a number", chapter)
+	}
+	chapters, err := remoteChapters(ctx, title, language, groups)
+	if err != nil {
+		return nil, err
+	}
+	for _, ch := range chapters {
+		if n, ok := ChapterNumber(ch.Attributes.Chapter); ok && n == want {
+			return ch, nil
+		}
+	}
+	return nil, fmt.Errorf("chapter %s of '%s' is not available on MangaDex", chapter, title)
+}
+
+func remoteChapters(ctx context.Context, title, language string, groups []string) ([]*api.ChapterData, error) {
+	mangaID, err := MangaIDFor(ctx, title)
+	if err != nil {
+		return nil, err
+	}
+	feed, err := api.FetchAllChaptersInLanguage(ctx, mangaID, language)
+	if err != nil {
+		return nil, err
+	}
+	return PickChapters(feed, groups), nil
+}
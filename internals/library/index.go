@@ -0,0 +1,142 @@
+package library
+
+import (
+	"encoding/json"
+	"manga-cli/internals/config"
+	"manga-cli/internals/filelock"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+const indexFileName = "library.json"
+
+type Index struct {
+	Manga map[string]*IndexEntry `json:"manga"`
+}
+
+type IndexEntry struct {
+	Title       string            `json:"title"`
+	MangaID     string            `json:"mangaId"`
+	Source      string            `json:"source"`
+	Titles      map[string]string `json:"titles,omitempty"`
+	Chapters    int               `json:"chapters"`
+	Latest      string            `json:"latest"`
+	SkipUpdates bool              `json:"skipUpdates,omitempty"`
+	UpdatedAt   time.Time         `json:"updatedAt"`
+}
+
+func indexPath() (string, error) {
+	dir, err := config.ConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, indexFileName), nil
+}
+
+func LoadIndex() (*Index, error) {
+	path, err := indexPath()
+	if err != nil {
+		return nil, err
+	}
+
+	idx := &Index{Manga: map[string]*IndexEntry{}}
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return idx, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal(data, idx); err != nil {
+		return nil, err
+	}
+	if idx.Manga == nil {
+		idx.Manga = map[string]*IndexEntry{}
+	}
+	return idx, nil
+}
+
+func SaveIndex(idx *Index) error {
+	path, err := indexPath()
+	if err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(idx, "", "  ")
+	if err != nil {
+		return err
+	}
+	return filelock.WriteFile(path, data)
+}
+
+// updateIndex refreshes the index entry of m. Callers hold the library lock.
+func updateIndex(m *Manifest) error {
+	idx, err := LoadIndex()
+	if err != nil {
+		return err
+	}
+
+	chapters := m.ChapterKeys()
+	latest := ""
+	if len(chapters) > 0 {
+		latest = chapters[len(chapters)-1]
+	}
+
+	idx.Manga[m.Title] = &IndexEntry{
+		Title:       m.Title,
+		MangaID:     m.MangaID,
+		Source:      m.Source,
+		Titles:      m.Titles,
+		Chapters:    len(chapters),
+		Latest:      latest,
+		SkipUpdates: m.SkipUpdates,
+		UpdatedAt:   m.UpdatedAt,
+	}
+	return SaveIndex(idx)
+}
+
+func (idx *Index) Entries() []*IndexEntry {
+	entries := make([]*IndexEntry, 0, len(idx.Manga))
+	for _, e := range idx.Manga {
+		entries = append(entries, e)
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		return strings.ToLower(entries[i].Title) < strings.ToLower(entries[j].Title)
+	})
+	return entries
+}
+
+func (idx *Index) FindByMangaID(mangaID string) *IndexEntry {
+	for _, e := range idx.Manga {
+		if e.MangaID == mangaID {
+			return e
+		}
+	}
+	return nil
+}
+
+func ResolveTitle(query string) string {
+	idx, err := LoadIndex()
+	if err != nil {
+		return query
+	}
+	if _, ok := idx.Manga[query]; ok {
+		return query
+	}
+
+	q := strings.ToLower(strings.TrimSpace(query))
+	for _, e := range idx.Entries() {
+		if strings.ToLower(e.Title) == q {
+			return e.Title
+		}
+		for _, t := range e.Titles {
+			if strings.ToLower(t) == q {
+				return e.Title
+			}
+		}
+	}
+	return query
+}
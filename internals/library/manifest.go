@@ -0,0 +1,242 @@
+package library
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"manga-cli/internals/config"
+	"manga-cli/internals/filelock"
+	"manga-cli/internals/utils"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+const manifestFileName = "manifest.json"
+const SourceMangaDex = "mangadex"
+
+// Manifest records a manga in the library and its chapters. Tags holds its
+// MangaDex tags: null until they have been looked up, empty when it has none.
+type Manifest struct {
+	MangaID     string                   `json:"mangaId"`
+	Source      string                   `json:"source"`
+	Title       string                   `json:"title"`
+	Titles      map[string]string        `json:"titles,omitempty"`
+	Chapters    map[string]*ChapterEntry `json:"chapters"`
+	Removed     []string                 `json:"removed,omitempty"`
+	Tags        []string                 `json:"tags"`
+	Processing  string                   `json:"processing,omitempty"`
+	SkipUpdates bool                     `json:"skipUpdates,omitempty"`
+	UpdatedAt   time.Time                `json:"updatedAt"`
+}
+
+type ChapterEntry struct {
+	ID           string    `json:"id"`
+	Chapter      string    `json:"chapter"`
+	Volume       string    `json:"volume,omitempty"`
+	Title        string    `json:"title,omitempty"`
+	Group        string    `json:"group,omitempty"`
+	Language     string    `json:"language,omitempty"`
+	DataSaver    bool      `json:"dataSaver,omitempty"`
+	Archive      string    `json:"archive,omitempty"`
+	Pages        []Page    `json:"pages"`
+	DownloadedAt time.Time `json:"downloadedAt"`
+}
+
+type Page struct {
+	File string `json:"file"`
+	Hash string `json:"sha256"`
+	Size int64  `json:"size"`
+}
+
+type ChapterInfo struct {
+	MangaID   string
+	Titles    map[string]string
+	Tags      []string
+	ChapterID string
+	Chapter   string
+	Volume    string
+	Title     string
+	Group     string
+	Language  string
+	DataSaver bool
+	Archive   string
+}
+
+func MangaDir(title string) (string, error) {
+	root, err := utils.GetOrCreateMangaCliDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(root, title), nil
+}
+
+func LoadManifest(title string) (*Manifest, error) {
+	dir, err := MangaDir(title)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := os.ReadFile(filepath.Join(dir, manifestFileName))
+	if err != nil {
+		return nil, err
+	}
+
+	var m Manifest
+	if err := json.Unmarshal(data, &m); err != nil {
+		return nil, fmt.Errorf("failed to parse manifest for '%s': %w", title, err)
+	}
+	if m.Chapters == nil {
+		m.Chapters = map[string]*ChapterEntry{}
+	}
+	return &m, nil
+}
+
+func SaveManifest(m *Manifest) error {
+	dir, err := MangaDir(m.Title)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return err
+	}
+	return filelock.WriteFile(filepath.Join(dir, manifestFileName), data)
+}
+
+// mu serialises library updates within this process; the lock file does the
+// same between the CLI, the daemon and the reader.
+var mu sync.Mutex
+
+// lock takes the library lock, which guards every manifest and the index,
+// and returns the function that releases it.
+func lock() (func(), error) {
+	path, err := indexPath()
+	if err != nil {
+		return nil, err
+	}
+	mu.Lock()
+	unlock, err := filelock.Lock(path + ".lock")
+	if err != nil {
+		mu.Unlock()
+		return nil, err
+	}
+	return func() {
+		unlock()
+		mu.Unlock()
+	}, nil
+}
+
+// updateManifest loads the manifest of title, applies fn and saves it along
+// with its index entry while holding the library lock. With create set a
+// missing manifest is started empty instead of failing.
+func updateManifest(title string, create bool, fn func(m *Manifest) error) error {
+	unlock, err := lock()
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	m, err := LoadManifest(title)
+	if os.IsNotExist(err) && create {
+		m, err = &Manifest{
+			Source:   SourceMangaDex,
+			Title:    title,
+			Chapters: map[string]*ChapterEntry{},
+		}, nil
+	}
+	if err != nil {
+		return err
+	}
+	if err := fn(m); err != nil {
+		return err
+	}
+	if err := SaveManifest(m); err != nil {
+		return err
+	}
+	return updateIndex(m)
+}
+
+func RecordChapter(title string, info ChapterInfo, chapterPath string, pages []string) error {
+	entry := &ChapterEntry{
+		ID:           info.ChapterID,
+		Chapter:      info.Chapter,
+		Volume:       info.Volume,
+		Title:        info.Title,
+		Group:        info.Group,
+		Language:     info.Language,
+		DataSaver:    info.DataSaver,
+		Archive:      info.Archive,
+		DownloadedAt: time.Now(),
+	}
+
+	for _, page := range pages {
+		hash, size, err := HashFile(filepath.Join(chapterPath, page))
+		if err != nil {
+			return fmt.Errorf("failed to hash page %s: %w", page, err)
+		}
+		entry.Pages = append(entry.Pages, Page{File: page, Hash: hash, Size: size})
+	}
+
+	return updateManifest(title, true, func(m *Manifest) error {
+		if info.MangaID != "" {
+			m.MangaID = info.MangaID
+		}
+		if len(info.Titles) > 0 {
+			m.Titles = info.Titles
+		}
+		if len(info.Tags) > 0 {
+			m.Tags = info.Tags
+		}
+		m.Chapters[info.Chapter] = entry
+		m.UpdatedAt = entry.DownloadedAt
+		return nil
+	})
+}
+
+func HashFile(path string) (string, int64, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", 0, err
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	n, err := io.Copy(h, f)
+	if err != nil {
+		return "", 0, err
+	}
+	return hex.EncodeToString(h.Sum(nil)), n, nil
+}
+
+// ProcessingSpec returns the image transforms for a manga: its own setting
+// when one is stored in the manifest, otherwise the global process option.
+func ProcessingSpec(title string) string {
+	if m, err := LoadManifest(title); err == nil && m.Processing != "" {
+		return m.Processing
+	}
+	if val, err := config.GetConfigOption("process"); err == nil && val != nil {
+		return fmt.Sprintf("%v", val)
+	}
+	return ""
+}
+
+// SetProcessingSpec stores the image transforms of a manga that is already
+// in the library.
+func SetProcessingSpec(title, spec string) error {
+	err := updateManifest(title, false, func(m *Manifest) error {
+		m.Processing = spec
+		return nil
+	})
+	if os.IsNotExist(err) {
+		return fmt.Errorf("'%s' is not in the library", title)
+	}
+	return err
+}
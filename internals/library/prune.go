@@ -0,0 +1,234 @@
+package library
+
+import (
+	"manga-cli/internals/history"
+	"manga-cli/internals/utils"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+const (
+	ReasonRead    = "read"
+	ReasonEmpty   = "empty"
+	ReasonPartial = "partial"
+	ReasonDeleted = "deleted"
+)
+
+type PruneOptions struct {
+	Read       bool
+	ReadBefore time.Time
+	Empty      bool
+	Partial    bool
+	// Unfinished holds "title/chapter" keys of chapters with queue jobs that
+	// have not completed, so their folders count as partial downloads.
+	Unfinished map[string]bool
+}
+
+type PruneItem struct {
+	Title   string
+	Chapter string
+	Path    string
+	Reason  string
+	Size    int64
+}
+
+func ChapterKey(title, chapter string) string {
+	return title + "/" + chapter
+}
+
+func FindPrunable(opts PruneOptions) ([]PruneItem, error) {
+	root, err := utils.GetOrCreateMangaCliDir()
+	if err != nil {
+		return nil, err
+	}
+	hist, err := history.Load()
+	if err != nil {
+		return nil, err
+	}
+
+	mangaDirs, err := os.ReadDir(root)
+	if err != nil {
+		return nil, err
+	}
+
+	var items []PruneItem
+	for _, md := range mangaDirs {
+		if !md.IsDir() {
+			continue
+		}
+		title := md.Name()
+		mangaPath := filepath.Join(root, title)
+		m, _ := LoadManifest(title)
+
+		chapterDirs, err := os.ReadDir(mangaPath)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, cd := range chapterDirs {
+			chapter, ok := chapterName(cd)
+			if !ok {
+				continue
+			}
+			path := filepath.Join(mangaPath, cd.Name())
+			size, _ := DirSize(path)
+			item := PruneItem{Title: title, Chapter: chapter, Path: path, Size: size}
+
+			switch {
+			case opts.Empty && cd.IsDir() && size == 0 && isEmptyTree(path):
+				item.Reason = ReasonEmpty
+			case opts.Partial && cd.IsDir() && (hasPartFiles(path) || opts.Unfinished[ChapterKey(title, chapter)]) && !inManifest(m, chapter):
+				item.Reason = ReasonPartial
+			case opts.Read && isReadBefore(hist.Get(title, chapter), opts.ReadBefore):
+				item.Reason = ReasonRead
+			default:
+				continue
+			}
+			items = append(items, item)
+		}
+
+		// A folder holding nothing but its manifest is empty, unless the
+		// manifest remembers removed chapters that update must not fetch again.
+		if opts.Empty && onlyManifest(chapterDirs) && (m == nil || len(m.Removed) == 0) {
+			size, _ := DirSize(mangaPath)
+			items = append(items, PruneItem{Title: title, Path: mangaPath, Reason: ReasonEmpty, Size: size})
+		}
+	}
+	return items, nil
+}
+
+func onlyManifest(entries []os.DirEntry) bool {
+	for _, e := range entries {
+		if e.Name() != manifestFileName {
+			return false
+		}
+	}
+	return true
+}
+
+func inManifest(m *Manifest, chapter string) bool {
+	if m == nil {
+		return false
+	}
+	_, ok := m.Chapters[chapter]
+	return ok
+}
+
+func isReadBefore(st *history.ChapterState, before time.Time) bool {
+	return st != nil && st.Completed && st.UpdatedAt.Before(before)
+}
+
+func hasPartFiles(dir string) bool {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return false
+	}
+	for _, e := range entries {
+		if strings.HasSuffix(e.Name(), ".part") {
+			return true
+		}
+	}
+	return false
+}
+
+func isEmptyTree(dir string) bool {
+	size, err := DirSize(dir)
+	if err != nil || size > 0 {
+		return false
+	}
+	entries, _ := os.ReadDir(dir)
+	for _, e := range entries {
+		if !e.IsDir() {
+			return false
+		}
+	}
+	return true
+}
+
+// Remove deletes the item. Read chapters go through RemoveChapter and empty
+// manga folders through RemoveManga so the manifest and index stay in sync;
+// empty and partial chapter folders were never recorded and are simply
+// removed.
+func (item PruneItem) Remove() (int64, error) {
+	switch {
+	case item.Reason == ReasonRead || item.Reason == ReasonDeleted:
+		return RemoveChapter(item.Title, item.Chapter)
+	case item.Chapter == "":
+		return RemoveManga(item.Title)
+	}
+	return item.Size, os.RemoveAll(item.Path)
+}
+
+// RemoveManga deletes a manga folder and drops it from the library index.
+func RemoveManga(title string) (int64, error) {
+	dir, err := MangaDir(title)
+	if err != nil {
+		return 0, err
+	}
+	size, err := DirSize(dir)
+	if err != nil && !os.IsNotExist(err) {
+		return 0, err
+	}
+
+	unlock, err := lock()
+	if err != nil {
+		return 0, err
+	}
+	defer unlock()
+
+	if err := os.RemoveAll(dir); err != nil {
+		return 0, err
+	}
+
+	idx, err := LoadIndex()
+	if err != nil {
+		return size, err
+	}
+	delete(idx.Manga, title)
+	return size, SaveIndex(idx)
+}
+
+// ChaptersInRanges lists the downloaded chapters of a manga that fall in
+// any of the given ranges, as deletable items.
+func ChaptersInRanges(title string, ranges []utils.ChapterRange) ([]PruneItem, error) {
+	dir, err := MangaDir(title)
+	if err != nil {
+		return nil, err
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var chapters []string
+	for _, e := range entries {
+		ch, ok := chapterName(e)
+		if !ok {
+			continue
+		}
+		n, ok := ChapterNumber(ch)
+		if !ok {
+			continue
+		}
+		for _, r := range ranges {
+			if r.Contains(n) {
+				chapters = append(chapters, ch)
+				break
+			}
+		}
+	}
+	SortChapters(chapters)
+
+	var items []PruneItem
+	for _, ch := range chapters {
+		path, err := utils.FindChapterPath(dir, ch)
+		if err != nil {
+			continue
+		}
+		size, _ := DirSize(path)
+		items = append(items, PruneItem{Title: title, Chapter: ch, Path: path, Reason: ReasonDeleted, Size: size})
+	}
+	return items, nil
+}
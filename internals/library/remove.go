@@ -0,0 +1,55 @@
+package library
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+)
+
+func DirSize(path string) (int64, error) {
+	var size int64
+	err := filepath.WalkDir(path, func(_ string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.IsDir() {
+			info, err := d.Info()
+			if err != nil {
+				return err
+			}
+			size += info.Size()
+		}
+		return nil
+	})
+	return size, err
+}
+
+// RemoveChapter deletes a chapter folder or archive and its manifest entry and returns
+// the number of bytes freed. The chapter number is remembered so update does
+// not treat it as missing and download it again.
+func RemoveChapter(title, chapter string) (int64, error) {
+	chapterPath, err := ChapterPath(title, chapter)
+	if err != nil && !os.IsNotExist(err) {
+		return 0, err
+	}
+	size, err := DirSize(chapterPath)
+	if err != nil && !os.IsNotExist(err) {
+		return 0, err
+	}
+	if err := os.RemoveAll(chapterPath); err != nil {
+		return 0, err
+	}
+
+	err = updateManifest(title, false, func(m *Manifest) error {
+		delete(m.Chapters, chapter)
+		if !slices.Contains(m.Removed, chapter) {
+			m.Removed = append(m.Removed, chapter)
+		}
+		return nil
+	})
+	if os.IsNotExist(err) {
+		return size, nil
+	}
+	return size, err
+}
@@ -0,0 +1,223 @@
+package library
+
+import (
+	"errors"
+	"fmt"
+	"manga-cli/internals/config"
+	"manga-cli/internals/history"
+	"manga-cli/internals/utils"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	defaultPageSize      = 400 << 10
+	defaultSaverPageSize = 120 << 10
+	defaultChapterPages  = 20
+)
+
+var ErrLowSpace = errors.New("not enough disk space")
+
+// EstimateChapterSize uses the pages already in the library to guess how
+// large a chapter will be, falling back to typical MangaDex sizes.
+func EstimateChapterSize(dataSaver bool) int64 {
+	var bytes, pages, chapters int64
+
+	if idx, err := LoadIndex(); err == nil {
+		for _, e := range idx.Manga {
+			m, err := LoadManifest(e.Title)
+			if err != nil {
+				continue
+			}
+			for _, ch := range m.Chapters {
+				if ch.DataSaver != dataSaver {
+					continue
+				}
+				chapters++
+				for _, p := range ch.Pages {
+					bytes += p.Size
+					pages++
+				}
+			}
+		}
+	}
+
+	if chapters == 0 || pages == 0 {
+		if dataSaver {
+			return defaultChapterPages * defaultSaverPageSize
+		}
+		return defaultChapterPages * defaultPageSize
+	}
+	return bytes / chapters
+}
+
+func DiskUsage() (int64, error) {
+	root, err := utils.GetOrCreateMangaCliDir()
+	if err != nil {
+		return 0, err
+	}
+	return DirSize(root)
+}
+
+func sizeOption(key string) int64 {
+	val, err := config.GetConfigOption(key)
+	if err != nil || val == nil {
+		return 0
+	}
+	size, err := utils.ParseSize(fmt.Sprintf("%v", val))
+	if err != nil {
+		return 0
+	}
+	return size
+}
+
+func stringOption(key, fallback string) string {
+	val, err := config.GetConfigOption(key)
+	if err != nil || val == nil || fmt.Sprintf("%v", val) == "" {
+		return fallback
+	}
+	return strings.ToLower(fmt.Sprintf("%v", val))
+}
+
+// CheckSpace makes sure there is room for the given number of chapters
+// without changing the library; MakeRoom is the step that evicts. Problems
+// are returned as warnings, or as an error wrapping ErrLowSpace when
+// low_space_action is "refuse".
+func CheckSpace(chapters int, dataSaver bool) ([]string, error) {
+	var warnings []string
+	estimate := int64(chapters) * EstimateChapterSize(dataSaver)
+	refuse := stringOption("low_space_action", "refuse") == "refuse"
+
+	problem := func(msg string) error {
+		if refuse {
+			return fmt.Errorf("%w: %s", ErrLowSpace, msg)
+		}
+		warnings = append(warnings, msg)
+		return nil
+	}
+
+	if quota := sizeOption("quota"); quota > 0 {
+		usage, err := DiskUsage()
+		if err != nil {
+			return nil, err
+		}
+
+		if usage+estimate > quota {
+			msg := fmt.Sprintf("library would grow to %s, over the %s quota",
+				utils.FormatBytes(usage+estimate), utils.FormatBytes(quota))
+			if err := problem(msg); err != nil {
+				return warnings, err
+			}
+		}
+	}
+
+	root, err := utils.GetOrCreateMangaCliDir()
+	if err != nil {
+		return warnings, err
+	}
+	free, err := utils.FreeSpace(root)
+	if err != nil {
+		return warnings, fmt.Errorf("failed to check free space: %w", err)
+	}
+
+	minFree := sizeOption("min_free_space")
+	if int64(free)-estimate < minFree {
+		msg := fmt.Sprintf("%s free on %s, about %s needed and %s must stay free",
+			utils.FormatBytes(int64(free)), root, utils.FormatBytes(estimate), utils.FormatBytes(minFree))
+		if err := problem(msg); err != nil {
+			return warnings, err
+		}
+	}
+
+	return warnings, nil
+}
+
+// MakeRoom evicts chapters with the configured eviction policy when
+// downloading the given number of chapters would take the library over its
+// quota. It returns a description of every chapter it removed. Callers run it
+// once before a batch of downloads, not for single chapters opened by the
+// reader.
+func MakeRoom(chapters int, dataSaver bool) ([]string, error) {
+	quota := sizeOption("quota")
+	policy := stringOption("eviction", "none")
+	if quota <= 0 || policy == "none" {
+		return nil, nil
+	}
+
+	usage, err := DiskUsage()
+	if err != nil {
+		return nil, err
+	}
+	over := usage + int64(chapters)*EstimateChapterSize(dataSaver) - quota
+	if over <= 0 {
+		return nil, nil
+	}
+	removed, _, err := Evict(policy, over)
+	return removed, err
+}
+
+type evictCandidate struct {
+	title   string
+	chapter string
+	when    time.Time
+}
+
+// Evict removes chapters until at least need bytes are freed. Only chapters
+// that have been read to the end are removed, and never the chapter of a
+// manga that was opened last, as it may still be on screen. The "oldest"
+// policy removes the earliest downloads first; "read-oldest" removes the
+// least recently read first.
+func Evict(policy string, need int64) ([]string, int64, error) {
+	if policy != "oldest" && policy != "read-oldest" {
+		return nil, 0, fmt.Errorf("unknown eviction policy '%s'", policy)
+	}
+
+	idx, err := LoadIndex()
+	if err != nil {
+		return nil, 0, err
+	}
+	hist, err := history.Load()
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var candidates []evictCandidate
+	for _, e := range idx.Manga {
+		m, err := LoadManifest(e.Title)
+		if err != nil {
+			continue
+		}
+		last, _ := hist.Last(e.Title)
+		for ch, entry := range m.Chapters {
+			st := hist.Get(e.Title, ch)
+			if st == nil || !st.Completed || ch == last {
+				continue
+			}
+			when := st.UpdatedAt
+			if policy == "oldest" {
+				when = entry.DownloadedAt
+			}
+			candidates = append(candidates, evictCandidate{e.Title, ch, when})
+		}
+	}
+
+	sort.Slice(candidates, func(i, j int) bool {
+		return candidates[i].when.Before(candidates[j].when)
+	})
+
+	var removed []string
+	var freed int64
+	for _, c := range candidates {
+		if freed >= need {
+			break
+		}
+		size, err := RemoveChapter(c.title, c.chapter)
+		if err != nil {
+			return removed, freed, err
+		}
+		freed += size
+		removed = append(removed, fmt.Sprintf("%s chapter %s (%s)", c.title, c.chapter, utils.FormatBytes(size)))
+	}
+	return removed, freed, nil
+}
@@ -0,0 +1,225 @@
+package library
+
+import (
+	"fmt"
+	"manga-cli/internals/archive"
+	"manga-cli/internals/history"
+	"manga-cli/internals/utils"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+type MangaStats struct {
+	Title        string    `json:"title"`
+	MangaID      string    `json:"mangaId,omitempty"`
+	Chapters     int       `json:"chapters"`
+	First        string    `json:"first,omitempty"`
+	Last         string    `json:"last,omitempty"`
+	Gaps         []string  `json:"gaps,omitempty"`
+	Pages        int       `json:"pages"`
+	Size         int64     `json:"size"`
+	LastDownload time.Time `json:"lastDownload,omitempty"`
+	Read         int       `json:"read"`
+	LastRead     string    `json:"lastRead,omitempty"`
+	LastReadPage int       `json:"lastReadPage,omitempty"`
+	LastReadDone bool      `json:"lastReadDone,omitempty"`
+}
+
+func (s MangaStats) Progress() float64 {
+	if s.Chapters == 0 {
+		return 0
+	}
+	return float64(s.Read) / float64(s.Chapters)
+}
+
+// Stats summarises every manga folder under the download path, using the
+// manifest where there is one and the folder contents otherwise.
+func Stats() ([]MangaStats, error) {
+	root, err := utils.GetOrCreateMangaCliDir()
+	if err != nil {
+		return nil, err
+	}
+	entries, err := os.ReadDir(root)
+	if err != nil {
+		return nil, err
+	}
+	hist, err := history.Load()
+	if err != nil {
+		return nil, err
+	}
+
+	var stats []MangaStats
+	for _, e := range entries {
+		if !e.IsDir() {
+			continue
+		}
+		s, err := statsFor(root, e.Name(), hist)
+		if err != nil {
+			return nil, err
+		}
+		stats = append(stats, s)
+	}
+	return stats, nil
+}
+
+func statsFor(root, title string, hist history.History) (MangaStats, error) {
+	s := MangaStats{Title: title}
+	mangaPath := filepath.Join(root, title)
+	m, _ := LoadManifest(title)
+	if m != nil {
+		s.MangaID = m.MangaID
+	}
+
+	chapters, err := LocalChapters(title)
+	if err != nil {
+		return s, err
+	}
+
+	for _, ch := range chapters {
+		chapterPath, _ := utils.FindChapterPath(mangaPath, ch)
+		entry := (*ChapterEntry)(nil)
+		if m != nil {
+			entry = m.Chapters[ch]
+		}
+
+		switch {
+		case archive.IsArchive(chapterPath):
+			if ar, err := archive.Open(chapterPath); err == nil {
+				s.Pages += len(archive.Images(ar.Names()))
+				ar.Close()
+			}
+		case entry != nil:
+			s.Pages += len(entry.Pages)
+		default:
+			files, _ := os.ReadDir(chapterPath)
+			for _, f := range files {
+				if !f.IsDir() {
+					s.Pages++
+				}
+			}
+		}
+
+		if entry != nil {
+			if entry.DownloadedAt.After(s.LastDownload) {
+				s.LastDownload = entry.DownloadedAt
+			}
+		} else if info, err := os.Stat(chapterPath); err == nil && info.ModTime().After(s.LastDownload) {
+			s.LastDownload = info.ModTime()
+		}
+		if st := hist.Get(title, ch); st != nil && st.Completed {
+			s.Read++
+		}
+	}
+
+	if ch, st := hist.Last(title); st != nil {
+		s.LastRead, s.LastReadPage, s.LastReadDone = ch, st.Page, st.Completed
+	}
+
+	s.Chapters = len(chapters)
+	if len(chapters) > 0 {
+		s.First = chapters[0]
+		s.Last = chapters[len(chapters)-1]
+	}
+
+	var removed []string
+	if m != nil {
+		removed = m.Removed
+	}
+	s.Gaps = FormatGaps(MissingChapters(chapters, removed))
+	s.Size, _ = DirSize(mangaPath)
+	return s, nil
+}
+
+// LocalChapters lists the chapter folders and archives of a manga in numeric
+// order.
+func LocalChapters(title string) ([]string, error) {
+	dir, err := MangaDir(title)
+	if err != nil {
+		return nil, err
+	}
+	return ChaptersIn(dir)
+}
+
+// ChaptersIn lists the chapter folders and archives in a manga folder in
+// numeric order.
+func ChaptersIn(dir string) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var chapters []string
+	for _, e := range entries {
+		if ch, ok := chapterName(e); ok {
+			chapters = append(chapters, ch)
+		}
+	}
+	SortChapters(chapters)
+	return chapters, nil
+}
+
+// MissingChapters returns the whole chapter numbers between the first and
+// last chapter that are neither present nor deliberately removed.
+func MissingChapters(chapters []string, removed []string) []int {
+	have := map[int]bool{}
+	lo, hi := math.MaxInt, math.MinInt
+	for _, list := range [][]string{chapters, removed} {
+		for _, ch := range list {
+			n, ok := ChapterNumber(ch)
+			if !ok {
+				continue
+			}
+			k := int(math.Floor(n))
+			have[k] = true
+			lo, hi = min(lo, k), max(hi, k)
+		}
+	}
+
+	var missing []int
+	for k := lo; k <= hi; k++ {
+		if !have[k] {
+			missing = append(missing, k)
+		}
+	}
+	return missing
+}
+
+// FormatGaps collapses consecutive numbers into ranges, e.g. 4-6, 9.
+func FormatGaps(missing []int) []string {
+	var out []string
+	for i := 0; i < len(missing); {
+		j := i
+		for j+1 < len(missing) && missing[j+1] == missing[j]+1 {
+			j++
+		}
+		if i == j {
+			out = append(out, fmt.Sprint(missing[i]))
+		} else {
+			out = append(out, fmt.Sprintf("%d-%d", missing[i], missing[j]))
+		}
+		i = j + 1
+	}
+	return out
+}
+
+// ReadingSummary describes the last chapter opened, e.g. "#12 p.5" or
+// "#12 ✓" when it was finished.
+func (s MangaStats) ReadingSummary() string {
+	if s.LastRead == "" {
+		return "-"
+	}
+	if s.LastReadDone {
+		return "#" + s.LastRead + " ✓"
+	}
+	return fmt.Sprintf("#%s p.%d", s.LastRead, s.LastReadPage)
+}
+
+func (s MangaStats) GapSummary() string {
+	if len(s.Gaps) == 0 {
+		return "-"
+	}
+	return strings.Join(s.Gaps, ",")
+}
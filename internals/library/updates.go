@@ -0,0 +1,102 @@
+package library
+
+import (
+	"context"
+	"fmt"
+	"manga-cli/internals/api"
+	"strings"
+)
+
+type Update struct {
+	Title    string
+	MangaID  string
+	Latest   float64
+	Chapters []*api.ChapterData
+}
+
+func (m *Manifest) HighestChapter() float64 {
+	highest := 0.0
+	for ch := range m.Chapters {
+		if n, ok := ChapterNumber(ch); ok && n > highest {
+			highest = n
+		}
+	}
+	for _, ch := range m.Removed {
+		if n, ok := ChapterNumber(ch); ok && n > highest {
+			highest = n
+		}
+	}
+	return highest
+}
+
+func SetSkipUpdates(title string, skip bool) error {
+	return updateManifest(title, false, func(m *Manifest) error {
+		m.SkipUpdates = skip
+		return nil
+	})
+}
+
+func CheckUpdates(ctx context.Context, title string, language string, groups []string) (*Update, error) {
+	m, err := LoadManifest(title)
+	if err != nil {
+		return nil, err
+	}
+	if m.MangaID == "" {
+		return nil, fmt.Errorf("no MangaDex ID recorded for '%s'", title)
+	}
+	return CheckUpdatesByID(ctx, title, m.MangaID, m.HighestChapter(), language, groups)
+}
+
+func CheckUpdatesByID(ctx context.Context, title, mangaID string, after float64, language string, groups []string) (*Update, error) {
+	feed, err := api.FetchAllChaptersInLanguage(ctx, mangaID, language)
+	if err != nil {
+		return nil, err
+	}
+
+	update := &Update{Title: title, MangaID: mangaID, Latest: after}
+	for _, ch := range PickChapters(feed, groups) {
+		if n, ok := ChapterNumber(ch.Attributes.Chapter); ok && n > after {
+			update.Chapters = append(update.Chapters, ch)
+		}
+	}
+	return update, nil
+}
+
+// PickChapters keeps one release per chapter number, preferring the listed
+// groups in order and falling back to whichever release the feed lists first.
+func PickChapters(feed []*api.ChapterData, groups []string) []*api.ChapterData {
+	rank := func(ch *api.ChapterData) int {
+		name := strings.ToLower(ch.GroupName())
+		for i, g := range groups {
+			if strings.ToLower(strings.TrimSpace(g)) == name {
+				return i
+			}
+		}
+		return len(groups)
+	}
+
+	picked := map[string]*api.ChapterData{}
+	var order []string
+	for _, ch := range feed {
+		key := ch.Attributes.Chapter
+		if key == "" {
+			continue
+		}
+		current, ok := picked[key]
+		if !ok {
+			picked[key] = ch
+			order = append(order, key)
+			continue
+		}
+		if rank(ch) < rank(current) {
+			picked[key] = ch
+		}
+	}
+
+	SortChapters(order)
+	result := make([]*api.ChapterData, 0, len(order))
+	for _, key := range order {
+		result = append(result, picked[key])
+	}
+	return result
+}
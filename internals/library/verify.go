@@ -0,0 +1,49 @@
+package library
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+type Problem struct {
+	Chapter string
+	File    string
+	Reason  string
+}
+
+func (p Problem) String() string {
+	return fmt.Sprintf("chapter %s: %s (%s)", p.Chapter, p.File, p.Reason)
+}
+
+func Verify(title string) ([]Problem, error) {
+	m, err := LoadManifest(title)
+	if err != nil {
+		return nil, err
+	}
+	dir, err := MangaDir(title)
+	if err != nil {
+		return nil, err
+	}
+
+	var problems []Problem
+	for _, ch := range m.ChapterKeys() {
+		entry := m.Chapters[ch]
+		for _, page := range entry.Pages {
+			path := filepath.Join(dir, ch, page.File)
+			if entry.Archive != "" {
+				path = filepath.Join(dir, page.File)
+			}
+			hash, size, err := HashFile(path)
+			switch {
+			case os.IsNotExist(err):
+				problems = append(problems, Problem{ch, page.File, "missing"})
+			case err != nil:
+				problems = append(problems, Problem{ch, page.File, err.Error()})
+			case size != page.Size || hash != page.Hash:
+				problems = append(problems, Problem{ch, page.File, "checksum mismatch"})
+			}
+		}
+	}
+	return problems, nil
+}
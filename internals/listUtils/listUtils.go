@@ -3,9 +3,13 @@ package listUtils
 import (
 	"fmt"
 	"io/fs"
+	"manga-cli/internals/history"
+	"manga-cli/internals/library"
+	"manga-cli/internals/utils"
 	"os"
 	"path/filepath"
 	"strings"
+	"text/tabwriter"
 )
 
 
@@ -59,3 +63,47 @@ func ListLocalDownloads(root string) error {
 		return nil
 	})
 }
+
+func PrintStatsTable(stats []library.MangaStats) {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "TITLE\tCHAPTERS\tRANGE\tGAPS\tPAGES\tSIZE\tLAST DOWNLOAD\tREAD\tLAST READ")
+	for _, s := range stats {
+		chapterRange := "-"
+		if s.Chapters == 1 {
+			chapterRange = s.First
+		} else if s.Chapters > 1 {
+			chapterRange = s.First + "-" + s.Last
+		}
+		last := "-"
+		if !s.LastDownload.IsZero() {
+			last = s.LastDownload.Format("2006-01-02")
+		}
+		fmt.Fprintf(w, "📁 %s\t%d\t%s\t%s\t%d\t%s\t%s\t%d/%d (%.0f%%)\t%s\n",
+			s.Title, s.Chapters, chapterRange, s.GapSummary(), s.Pages, utils.FormatBytes(s.Size), last, s.Read, s.Chapters, s.Progress()*100, s.ReadingSummary())
+	}
+	w.Flush()
+}
+
+// ListManifestChapters prints the chapters of a manga with a reading marker:
+// ✓ for read, ▶ with the page for started and nothing for unread chapters.
+func ListManifestChapters(m *library.Manifest, hist history.History) {
+	for _, ch := range m.ChapterKeys() {
+		entry := m.Chapters[ch]
+		group := entry.Group
+		if group == "" {
+			group = "unknown group"
+		}
+
+		marker := " "
+		progress := ""
+		if st := hist.Get(m.Title, ch); st != nil {
+			if st.Completed {
+				marker = "✓"
+			} else {
+				marker = "▶"
+				progress = fmt.Sprintf("  (page %d)", st.Page)
+			}
+		}
+		fmt.Printf("%s 📁 %-8s %3d pages  [%s, %s]  %s%s\n", marker, ch, len(entry.Pages), group, entry.Language, entry.DownloadedAt.Format("2006-01-02"), progress)
+	}
+}
@@ -0,0 +1,95 @@
+package progress
+
+import (
+	"fmt"
+	"io"
+	"manga-cli/internals/utils"
+	"os"
+	"time"
+)
+
+type Reporter interface {
+	SetTotal(chapters int)
+	StartChapter(label string, pages int)
+	PageBytes(n int64)
+	PageDone(page string, skipped bool)
+	PageFailed(page string, err error)
+	Retry(page string, attempt int, err error)
+	FinishChapter(err error)
+	Logf(format string, args ...any)
+	Close()
+}
+
+// New returns a live multi-line renderer when out is a terminal and a plain
+// line-per-event reporter otherwise.
+func New(out *os.File) Reporter {
+	if IsTerminal(out) {
+		return newTTY(out)
+	}
+	return Plain(out)
+}
+
+func IsTerminal(f *os.File) bool {
+	fi, err := f.Stat()
+	if err != nil {
+		return false
+	}
+	return fi.Mode()&os.ModeCharDevice != 0
+}
+
+type plain struct {
+	out   io.Writer
+	label string
+	pages int
+	page  int
+	start time.Time
+	bytes int64
+}
+
+func Plain(out io.Writer) Reporter {
+	return &plain{out: out}
+}
+
+func (p *plain) SetTotal(int) {}
+
+func (p *plain) StartChapter(label string, pages int) {
+	p.label, p.pages, p.page, p.bytes = label, pages, 0, 0
+	p.start = time.Now()
+	fmt.Fprintf(p.out, " Found %d pages to download.\n", pages)
+}
+
+func (p *plain) PageBytes(n int64) {
+	p.bytes += n
+}
+
+func (p *plain) PageDone(page string, skipped bool) {
+	p.page++
+	if skipped {
+		fmt.Fprintf(p.out, "[%d/%d] Skipped (exists): %s\n", p.page, p.pages, page)
+		return
+	}
+	fmt.Fprintf(p.out, "[%d/%d] Downloaded: %s\n", p.page, p.pages, page)
+}
+
+func (p *plain) PageFailed(page string, err error) {
+	p.page++
+	fmt.Fprintf(p.out, "[%d/%d] Failed %s: %v\n", p.page, p.pages, page, err)
+}
+
+func (p *plain) Retry(page string, attempt int, err error) {
+	fmt.Fprintf(p.out, "[%d/%d] Retrying %s (attempt %d): %v\n", p.page+1, p.pages, page, attempt, err)
+}
+
+func (p *plain) FinishChapter(err error) {
+	if err != nil {
+		return
+	}
+	elapsed := time.Since(p.start)
+	fmt.Fprintf(p.out, "✅ Downloaded %s (%s in %s)\n", p.label, utils.FormatBytes(p.bytes), elapsed.Round(time.Second))
+}
+
+func (p *plain) Logf(format string, args ...any) {
+	fmt.Fprintf(p.out, format+"\n", args...)
+}
+
+func (p *plain) Close() {}
@@ -0,0 +1,166 @@
+package progress
+
+import (
+	"fmt"
+	"io"
+	"manga-cli/internals/utils"
+	"strings"
+	"sync"
+	"time"
+)
+
+const barWidth = 30
+const redrawInterval = 100 * time.Millisecond
+
+type tty struct {
+	mu  sync.Mutex
+	out io.Writer
+
+	total     int
+	finished  int
+	label     string
+	pages     int
+	page      int
+	failed    int
+	retries   int
+	bytes     int64
+	start     time.Time
+	active    bool
+	drawn     int
+	lastDrawn time.Time
+}
+
+func newTTY(out io.Writer) Reporter {
+	return &tty{out: out}
+}
+
+func (t *tty) SetTotal(chapters int) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.total = chapters
+}
+
+func (t *tty) StartChapter(label string, pages int) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.label, t.pages = label, pages
+	t.page, t.failed, t.retries, t.bytes = 0, 0, 0, 0
+	t.start = time.Now()
+	t.active = true
+	t.redraw(true)
+}
+
+func (t *tty) PageBytes(n int64) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.bytes += n
+	t.redraw(false)
+}
+
+func (t *tty) PageDone(string, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.page++
+	t.redraw(true)
+}
+
+func (t *tty) PageFailed(page string, err error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.page++
+	t.failed++
+	t.printAbove(fmt.Sprintf("   ⚠ %s: %v", page, err))
+}
+
+func (t *tty) Retry(string, int, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.retries++
+	t.redraw(true)
+}
+
+func (t *tty) FinishChapter(err error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.active = false
+	t.finished++
+
+	elapsed := time.Since(t.start).Round(time.Second)
+	if err != nil {
+		t.printAbove(fmt.Sprintf("❌ %-30s %d/%d pages  %v", t.label, t.page-t.failed, t.pages, err))
+		return
+	}
+	t.printAbove(fmt.Sprintf("✅ %-30s %d pages  %s in %s", t.label, t.pages, utils.FormatBytes(t.bytes), elapsed))
+}
+
+func (t *tty) Logf(format string, args ...any) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.printAbove(fmt.Sprintf(format, args...))
+}
+
+func (t *tty) Close() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.clear()
+}
+
+func (t *tty) clear() {
+	if t.drawn > 0 {
+		fmt.Fprintf(t.out, "\033[%dA\033[J", t.drawn)
+		t.drawn = 0
+	}
+}
+
+func (t *tty) printAbove(line string) {
+	t.clear()
+	fmt.Fprintln(t.out, line)
+	t.redraw(true)
+}
+
+func (t *tty) redraw(force bool) {
+	if !force && time.Since(t.lastDrawn) < redrawInterval {
+		return
+	}
+	t.lastDrawn = time.Now()
+
+	var lines []string
+	if t.active {
+		elapsed := time.Since(t.start)
+		rate := float64(t.bytes) / max(elapsed.Seconds(), 0.001)
+		eta := "--:--"
+		if t.page > 0 && t.page < t.pages {
+			remaining := time.Duration(float64(elapsed) / float64(t.page) * float64(t.pages-t.page))
+			eta = formatDuration(remaining)
+		}
+		line := fmt.Sprintf("⬇ %-30s %s %3d/%-3d %9s/s  ETA %s", t.label, bar(t.page, t.pages), t.page, t.pages, utils.FormatBytes(int64(rate)), eta)
+		if t.retries > 0 {
+			line += fmt.Sprintf("  retries %d", t.retries)
+		}
+		lines = append(lines, line)
+	}
+	if t.total > 1 {
+		lines = append(lines, fmt.Sprintf("  %-30s %s %3d/%-3d chapters", "overall", bar(t.finished, t.total), t.finished, t.total))
+	}
+
+	t.clear()
+	for _, l := range lines {
+		fmt.Fprintf(t.out, "\033[2K%s\n", l)
+	}
+	t.drawn = len(lines)
+}
+
+func bar(done, total int) string {
+	filled := 0
+	if total > 0 {
+		filled = min(barWidth, done*barWidth/total)
+	}
+	return "[" + strings.Repeat("█", filled) + strings.Repeat("░", barWidth-filled) + "]"
+}
+
+func formatDuration(d time.Duration) string {
+	d = d.Round(time.Second)
+	m := int(d / time.Minute)
+	s := int((d % time.Minute) / time.Second)
+	return fmt.Sprintf("%02d:%02d", m, s)
+}
@@ -0,0 +1,326 @@
+package queue
+
+import (
+	"encoding/json"
+	"fmt"
+	"manga-cli/internals/config"
+	"manga-cli/internals/filelock"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+const queueFileName = "queue.json"
+
+type Status string
+
+const (
+	StatusPending Status = "pending"
+	StatusRunning Status = "running"
+	StatusPaused  Status = "paused"
+	StatusDone    Status = "done"
+	StatusFailed  Status = "failed"
+)
+
+type Job struct {
+	ID        int       `json:"id"`
+	Title     string    `json:"title"`
+	ChapterID string    `json:"chapterId"`
+	Chapter   string    `json:"chapter"`
+	DataSaver bool      `json:"dataSaver,omitempty"`
+	Status    Status    `json:"status"`
+	Attempts  int       `json:"attempts"`
+	Error     string    `json:"error,omitempty"`
+	PID       int       `json:"pid,omitempty"`
+	AddedAt   time.Time `json:"addedAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+// mu serialises queue updates within this process; the lock file does the
+// same between the CLI and the daemon.
+var mu sync.Mutex
+
+type Queue struct {
+	NextID int    `json:"nextId"`
+	Jobs   []*Job `json:"jobs"`
+}
+
+func queuePath() (string, error) {
+	dir, err := config.ConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, queueFileName), nil
+}
+
+func Load() (*Queue, error) {
+	path, err := queuePath()
+	if err != nil {
+		return nil, err
+	}
+
+	q := &Queue{NextID: 1}
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return q, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, q); err != nil {
+		return nil, fmt.Errorf("failed to parse download queue: %w", err)
+	}
+	return q, nil
+}
+
+func (q *Queue) Save() error {
+	path, err := queuePath()
+	if err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(q, "", "  ")
+	if err != nil {
+		return err
+	}
+	return filelock.WriteFile(path, data)
+}
+
+// lock takes the queue lock file, waiting while another manga-cli process
+// holds it, and returns the function that releases it.
+func lock() (func(), error) {
+	path, err := queuePath()
+	if err != nil {
+		return nil, err
+	}
+	return filelock.Lock(path + ".lock")
+}
+
+func update(fn func(q *Queue) error) error {
+	mu.Lock()
+	defer mu.Unlock()
+
+	unlock, err := lock()
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	q, err := Load()
+	if err != nil {
+		return err
+	}
+	if err := fn(q); err != nil {
+		return err
+	}
+	return q.Save()
+}
+
+func (q *Queue) find(id int) *Job {
+	for _, j := range q.Jobs {
+		if j.ID == id {
+			return j
+		}
+	}
+	return nil
+}
+
+func (q *Queue) active(chapterID string) *Job {
+	for _, j := range q.Jobs {
+		if j.ChapterID == chapterID && j.Status != StatusDone {
+			return j
+		}
+	}
+	return nil
+}
+
+// Add appends jobs to the queue and returns the IDs they were stored under.
+// A chapter that is already queued and not yet done keeps its existing job,
+// which is made pending again if it had failed or was paused.
+func Add(jobs []Job) ([]int, error) {
+	var ids []int
+	err := update(func(q *Queue) error {
+		now := time.Now()
+		for _, j := range jobs {
+			if existing := q.active(j.ChapterID); existing != nil {
+				if existing.Status == StatusFailed || existing.Status == StatusPaused {
+					existing.Status = StatusPending
+					existing.Error = ""
+					existing.UpdatedAt = now
+				}
+				ids = append(ids, existing.ID)
+				continue
+			}
+			job := j
+			job.ID = q.NextID
+			job.Status = StatusPending
+			job.AddedAt = now
+			job.UpdatedAt = now
+			q.NextID++
+			q.Jobs = append(q.Jobs, &job)
+			ids = append(ids, job.ID)
+		}
+		return nil
+	})
+	return ids, err
+}
+
+// Transition moves jobs in one of the from states to the to state. With no
+// ids every matching job is moved. It returns how many jobs changed.
+func Transition(ids []int, from []Status, to Status) (int, error) {
+	changed := 0
+	err := update(func(q *Queue) error {
+		for _, j := range selectJobs(q, ids) {
+			if !hasStatus(j, from) {
+				continue
+			}
+			j.Status = to
+			j.PID = 0
+			j.UpdatedAt = time.Now()
+			if to == StatusPending {
+				j.Error = ""
+			}
+			changed++
+		}
+		return nil
+	})
+	return changed, err
+}
+
+func Pause(ids []int) (int, error) {
+	return Transition(ids, []Status{StatusPending}, StatusPaused)
+}
+
+func Resume(ids []int) (int, error) {
+	return Transition(ids, []Status{StatusPaused}, StatusPending)
+}
+
+func Retry(ids []int) (int, error) {
+	return Transition(ids, []Status{StatusFailed}, StatusPending)
+}
+
+// Clear removes finished jobs, or every job that is not running when all is set.
+func Clear(all bool) (int, error) {
+	removed := 0
+	err := update(func(q *Queue) error {
+		kept := q.Jobs[:0]
+		for _, j := range q.Jobs {
+			if j.Status == StatusDone || (all && j.Status != StatusRunning) {
+				removed++
+				continue
+			}
+			kept = append(kept, j)
+		}
+		q.Jobs = kept
+		return nil
+	})
+	return removed, err
+}
+
+// Recover puts jobs left running by an interrupted process back to pending.
+// Jobs whose process is still alive are left to it.
+func Recover() error {
+	return update(func(q *Queue) error {
+		for _, j := range q.Jobs {
+			if j.Status != StatusRunning || j.ownerAlive() {
+				continue
+			}
+			j.Status = StatusPending
+			j.PID = 0
+			j.Error = ""
+			j.UpdatedAt = time.Now()
+		}
+		return nil
+	})
+}
+
+// Busy returns the jobs among ids that another process is running, so
+// they can be reported rather than waited for.
+func Busy(ids []int) ([]Job, error) {
+	q, err := Load()
+	if err != nil {
+		return nil, err
+	}
+	var busy []Job
+	for _, j := range selectJobs(q, ids) {
+		if j.Status == StatusRunning && j.ownerAlive() {
+			busy = append(busy, *j)
+		}
+	}
+	return busy, nil
+}
+
+// ownerAlive reports whether the process that claimed j is still running.
+// Jobs claimed before owners were recorded have no PID.
+func (j *Job) ownerAlive() bool {
+	return j.PID != 0 && j.PID != os.Getpid() && processAlive(j.PID)
+}
+
+func claim(filter func(*Job) bool) (*Job, error) {
+	var claimed *Job
+	err := update(func(q *Queue) error {
+		for _, j := range q.Jobs {
+			if j.Status != StatusPending || (filter != nil && !filter(j)) {
+				continue
+			}
+			j.Status = StatusRunning
+			j.PID = os.Getpid()
+			j.Attempts++
+			j.UpdatedAt = time.Now()
+			copied := *j
+			claimed = &copied
+			return nil
+		}
+		return nil
+	})
+	return claimed, err
+}
+
+func finish(id int, jobErr error) error {
+	return update(func(q *Queue) error {
+		j := q.find(id)
+		if j == nil {
+			return nil
+		}
+		j.PID = 0
+		j.UpdatedAt = time.Now()
+		if jobErr != nil {
+			j.Status = StatusFailed
+			j.Error = jobErr.Error()
+		} else {
+			j.Status = StatusDone
+			j.Error = ""
+		}
+		return nil
+	})
+}
+
+func selectJobs(q *Queue, ids []int) []*Job {
+	if len(ids) == 0 {
+		return q.Jobs
+	}
+	var jobs []*Job
+	for _, id := range ids {
+		if j := q.find(id); j != nil {
+			jobs = append(jobs, j)
+		}
+	}
+	return jobs
+}
+
+func hasStatus(j *Job, statuses []Status) bool {
+	for _, s := range statuses {
+		if j.Status == s {
+			return true
+		}
+	}
+	return false
+}
+
+func (q *Queue) Counts() map[Status]int {
+	counts := map[Status]int{}
+	for _, j := range q.Jobs {
+		counts[j.Status]++
+	}
+	return counts
+}
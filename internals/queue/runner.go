@@ -0,0 +1,74 @@
+package queue
+
+import (
+	"context"
+	"manga-cli/internals/downloader"
+	"manga-cli/internals/library"
+)
+
+type Result struct {
+	Job Job
+	Err error
+}
+
+// Process downloads pending jobs one at a time until none matching filter
+// remain or ctx is cancelled. Job state is persisted after every transition so
+// an interrupted run can be picked up again later; a job cut short by
+// cancellation goes back to pending rather than failed. Free space and the
+// library quota are checked before every job unless force is set; making room
+// under the quota is left to the caller, see library.MakeRoom.
+func Process(ctx context.Context, filter func(*Job) bool, force bool) ([]Result, error) {
+	var results []Result
+	for {
+		if err := ctx.Err(); err != nil {
+			return results, err
+		}
+
+		job, err := claim(filter)
+		if err != nil {
+			return results, err
+		}
+		if job == nil {
+			return results, nil
+		}
+
+		if !force {
+			warnings, err := library.CheckSpace(1, job.DataSaver)
+			for _, w := range warnings {
+				downloader.Reporter().Logf("⚠ %s", w)
+			}
+			if err != nil {
+				if _, terr := Transition([]int{job.ID}, []Status{StatusRunning}, StatusPending); terr != nil {
+					return results, terr
+				}
+				return results, err
+			}
+		}
+
+		jobErr := downloader.DownloadChapter(ctx, job.Title, job.ChapterID, job.Chapter, job.DataSaver)
+		if downloader.IsCancelled(jobErr) {
+			if _, err := Transition([]int{job.ID}, []Status{StatusRunning}, StatusPending); err != nil {
+				return results, err
+			}
+			return results, jobErr
+		}
+		if err := finish(job.ID, jobErr); err != nil {
+			return results, err
+		}
+
+		if jobErr != nil {
+			downloader.Reporter().Logf("Error downloading %s chapter %s: %v", job.Title, job.Chapter, jobErr)
+		}
+		results = append(results, Result{Job: *job, Err: jobErr})
+	}
+}
+
+func OnlyIDs(ids []int) func(*Job) bool {
+	set := map[int]bool{}
+	for _, id := range ids {
+		set[id] = true
+	}
+	return func(j *Job) bool {
+		return set[j.ID]
+	}
+}
@@ -0,0 +1,91 @@
+package queue
+
+import (
+	"fmt"
+	"manga-cli/internals/config"
+	"strings"
+	"time"
+)
+
+// Window is a daily time range, in local time, during which queued downloads
+// may run. End before Start means the window wraps past midnight.
+type Window struct {
+	Start time.Duration
+	End   time.Duration
+	raw   string
+}
+
+func ParseWindow(s string) (*Window, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return nil, nil
+	}
+
+	parts := strings.FieldsFunc(s, func(r rune) bool { return r == '-' || r == '–' })
+	if len(parts) != 2 {
+		return nil, fmt.Errorf("invalid download window %q, expected HH:MM-HH:MM", s)
+	}
+
+	start, err := parseClock(parts[0])
+	if err != nil {
+		return nil, err
+	}
+	end, err := parseClock(parts[1])
+	if err != nil {
+		return nil, err
+	}
+	return &Window{Start: start, End: end, raw: s}, nil
+}
+
+func parseClock(s string) (time.Duration, error) {
+	t, err := time.Parse("15:04", strings.TrimSpace(s))
+	if err != nil {
+		return 0, fmt.Errorf("invalid time %q, expected HH:MM", s)
+	}
+	return time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute, nil
+}
+
+func ConfiguredWindow() (*Window, error) {
+	val, err := config.GetConfigOption("download_window")
+	if err != nil || val == nil {
+		return nil, err
+	}
+	return ParseWindow(fmt.Sprintf("%v", val))
+}
+
+func (w *Window) String() string {
+	return w.raw
+}
+
+func sinceMidnight(t time.Time) time.Duration {
+	return time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute + time.Duration(t.Second())*time.Second
+}
+
+func (w *Window) Contains(t time.Time) bool {
+	if w == nil || w.Start == w.End {
+		return true
+	}
+	now := sinceMidnight(t)
+	if w.Start < w.End {
+		return now >= w.Start && now < w.End
+	}
+	return now >= w.Start || now < w.End
+}
+
+// Until returns how long to wait from t until the window next opens.
+func (w *Window) Until(t time.Time) time.Duration {
+	if w.Contains(t) {
+		return 0
+	}
+	wait := w.Start - sinceMidnight(t)
+	if wait < 0 {
+		wait += 24 * time.Hour
+	}
+	return wait
+}
+
+// InWindow is a Process filter that stops picking up new jobs once the
+// window has closed.
+func (w *Window) InWindow(*Job) bool {
+	return w.Contains(time.Now())
+}
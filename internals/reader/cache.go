@@ -0,0 +1,341 @@
+package readerUtil
+
+import (
+	"bytes"
+	"container/list"
+	"errors"
+	"fmt"
+	"image"
+	"image/png"
+	"manga-cli/internals/config"
+	"manga-cli/internals/imageproc"
+	"manga-cli/internals/utils"
+	"math"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+)
+
+const (
+	defaultPrefetch = 2
+	prefetchWorkers = 2
+)
+
+var errCacheClosed = errors.New("page cache closed")
+
+// pageCache renders pages of a chapter ahead of time. The current page is
+// rendered on demand and the next and previous few pages in background
+// goroutines, so turning a page only has to print the result. For the
+// built-in renderer the cached result is the terminal output itself; for
+// external viewers it is a copy of the page scaled down to the viewer box.
+// Least recently used pages are dropped once the cache is full.
+type pageCache struct {
+	pages    PageSource
+	dir      string
+	ahead    int
+	capacity int
+
+	mu      sync.Mutex
+	entries map[int]*cachedPage
+	order   *list.List
+	workers chan struct{}
+	closed  chan struct{}
+	wg      sync.WaitGroup
+}
+
+// pageSize is the cell box a page is rendered for. Decoded pages are kept
+// as images for views that compose several pages: scaled down to fit the
+// box, or to its width when height is zero.
+type pageSize struct {
+	width, height int
+	decoded       bool
+}
+
+type cachedPage struct {
+	index  int
+	size   pageSize
+	done   chan struct{}
+	elem   *list.Element
+	source string
+	path   string
+	out    []byte
+	img    image.Image
+	err    error
+}
+
+// newPageCache wraps pages; closing the cache closes pages too.
+func newPageCache(pages PageSource) (*pageCache, error) {
+	dir, err := makeTempDir("manga-cli-pages-*")
+	if err != nil {
+		return nil, err
+	}
+	ahead := prefetchPages()
+	return &pageCache{
+		pages:    pages,
+		dir:      dir,
+		ahead:    ahead,
+		capacity: 2*ahead + 3,
+		entries:  map[int]*cachedPage{},
+		order:    list.New(),
+		workers:  make(chan struct{}, prefetchWorkers),
+		closed:   make(chan struct{}),
+	}, nil
+}
+
+// prefetchPages reads how many pages to prepare on each side of the current
+// one from the "prefetch" config key.
+func prefetchPages() int {
+	val, err := config.GetConfigOption("prefetch")
+	if err != nil || val == nil {
+		return defaultPrefetch
+	}
+	switch v := val.(type) {
+	case float64:
+		return max(int(v), 0)
+	case int:
+		return max(v, 0)
+	case string:
+		if n, err := strconv.Atoi(v); err == nil {
+			return max(n, 0)
+		}
+	}
+	return defaultPrefetch
+}
+
+func (c *pageCache) Len() int { return c.pages.Len() }
+
+// Show returns page i rendered for size, waiting for it if needed, and
+// starts preparing the pages around it.
+func (c *pageCache) Show(i int, size pageSize) *cachedPage {
+	if f, ok := c.pages.(focuser); ok {
+		f.Focus(i, c.ahead)
+	}
+	p := c.get(i, size, false)
+	for j := 1; j <= c.ahead; j++ {
+		if i+j < c.pages.Len() {
+			c.get(i+j, size, true)
+		}
+		if i-j >= 0 {
+			c.get(i-j, size, true)
+		}
+	}
+	c.touch(p)
+	<-p.done
+
+	if stale(p) {
+		// The source dropped the file, e.g. a streamed page that fell out
+		// of its window while it was decoded or before a viewer got it.
+		// Render it again.
+		c.drop(p)
+		p = c.get(i, size, false)
+		<-p.done
+	}
+	return p
+}
+
+// stale reports whether the file a finished page depends on is gone: the
+// file handed to an external viewer, or the source a render failed on.
+func stale(p *cachedPage) bool {
+	file := p.path
+	if p.err != nil {
+		file = p.source
+	}
+	if file == "" {
+		return false
+	}
+	_, err := os.Stat(file)
+	return err != nil
+}
+
+// get returns the cache entry of page i, starting to render it when it is
+// missing or was rendered for another size.
+func (c *pageCache) get(i int, size pageSize, background bool) *cachedPage {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if p, ok := c.entries[i]; ok {
+		if p.size == size {
+			return p
+		}
+		c.remove(p)
+	}
+
+	p := &cachedPage{index: i, size: size, done: make(chan struct{})}
+	p.elem = c.order.PushFront(p)
+	c.entries[i] = p
+	c.trim()
+
+	c.wg.Add(1)
+	go func() {
+		defer c.wg.Done()
+		if background {
+			select {
+			case c.workers <- struct{}{}:
+				defer func() { <-c.workers }()
+			case <-c.closed:
+				p.err = errCacheClosed
+				close(p.done)
+				return
+			}
+		}
+		c.render(p)
+		close(p.done)
+	}()
+	return p
+}
+
+func (c *pageCache) touch(p *cachedPage) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.entries[p.index] == p {
+		c.order.MoveToFront(p.elem)
+	}
+}
+
+func (c *pageCache) drop(p *cachedPage) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.entries[p.index] == p {
+		c.remove(p)
+	}
+}
+
+// trim drops the least recently used finished pages beyond the capacity.
+// Pages still being rendered are kept.
+func (c *pageCache) trim() {
+	for e := c.order.Back(); e != nil && c.order.Len() > c.capacity; {
+		prev := e.Prev()
+		p := e.Value.(*cachedPage)
+		select {
+		case <-p.done:
+			c.remove(p)
+		default:
+		}
+		e = prev
+	}
+}
+
+// remove deletes p from the cache; the caller holds mu. A page that is
+// still rendering cleans up its own file once it is done.
+func (c *pageCache) remove(p *cachedPage) {
+	c.order.Remove(p.elem)
+	delete(c.entries, p.index)
+	select {
+	case <-p.done:
+		c.removeFile(p)
+	default:
+	}
+}
+
+func (c *pageCache) removeFile(p *cachedPage) {
+	if p.path != "" && filepath.Dir(p.path) == c.dir {
+		os.Remove(p.path)
+	}
+}
+
+func (c *pageCache) render(p *cachedPage) {
+	p.source, p.err = c.pages.Page(p.index)
+	if p.err != nil {
+		return
+	}
+
+	img, _, err := imageproc.Decode(p.source)
+	if p.size.decoded {
+		p.img, p.err = img, err
+		switch {
+		case err != nil:
+		case p.size.height == 0:
+			p.img = scaleToWidth(img, p.size.width)
+		default:
+			p.img = scaleToFit(img, p.size.width, p.size.height)
+		}
+		return
+	}
+	if builtin != nil {
+		if err != nil {
+			p.err = err
+			return
+		}
+		var buf bytes.Buffer
+		p.err = builtin.Draw(&buf, scaleToFit(img, p.size.width, p.size.height), p.size.width, p.size.height)
+		p.out = buf.Bytes()
+		return
+	}
+
+	// External viewers get the original file when it cannot be decoded
+	// here or is already small enough.
+	p.path = p.source
+	if err != nil {
+		return
+	}
+	scaled := scaleToFit(img, p.size.width, p.size.height)
+	if scaled == img {
+		return
+	}
+	path := filepath.Join(c.dir, fmt.Sprintf("%04d-%dx%d.png", p.index+1, p.size.width, p.size.height))
+	if err := writePNG(path, scaled); err == nil {
+		p.path = path
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.entries[p.index] != p {
+		c.removeFile(p)
+	}
+}
+
+// Close drops the prefetches that have not started, waits for renders in
+// progress, and then closes the page source and removes the cached files.
+func (c *pageCache) Close() error {
+	close(c.closed)
+	c.wg.Wait()
+	err := c.pages.Close()
+	removeTempDir(c.dir)
+	return err
+}
+
+// scaleToFit shrinks img to the pixel size of the cell box it is shown in,
+// so large pages are not scaled again on every turn. Smaller images are
+// returned as is.
+func scaleToFit(img image.Image, cols, rows int) image.Image {
+	size, _ := utils.TerminalSize()
+	cellW, cellH := size.CellSize()
+	c, r := fitCells(img.Bounds(), cols, rows)
+
+	b := img.Bounds()
+	scale := math.Min(float64(c*cellW)/float64(b.Dx()), float64(r*cellH)/float64(b.Dy()))
+	if scale >= 1 {
+		return img
+	}
+	return imageproc.Resize(img, max(int(float64(b.Dx())*scale), 1), max(int(float64(b.Dy())*scale), 1))
+}
+
+// scaleToWidth scales img to the pixel width of cols cells.
+func scaleToWidth(img image.Image, cols int) image.Image {
+	size, _ := utils.TerminalSize()
+	cellW, _ := size.CellSize()
+
+	b := img.Bounds()
+	width := cols * cellW
+	if width <= 0 || width == b.Dx() {
+		return img
+	}
+	return imageproc.Resize(img, width, max(b.Dy()*width/b.Dx(), 1))
+}
+
+func writePNG(path string, img image.Image) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	enc := png.Encoder{CompressionLevel: png.BestSpeed}
+	err = enc.Encode(f, img)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(path)
+	}
+	return err
+}
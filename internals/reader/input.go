@@ -0,0 +1,75 @@
+package readerUtil
+
+import (
+	"bufio"
+	"os"
+	"strings"
+	"sync"
+
+	"golang.org/x/term"
+)
+
+// keyReader reads single key presses from stdin. The terminal is only put in
+// raw mode while waiting for a key, so viewers and error messages print
+// normally in between. When stdin is not a terminal it falls back to reading
+// whole lines. mu guards state, which the signal handler restores from
+// another goroutine.
+type keyReader struct {
+	fd    int
+	raw   bool
+	mu    sync.Mutex
+	state *term.State
+	lines *bufio.Reader
+}
+
+func newKeyReader() *keyReader {
+	k := &keyReader{fd: int(os.Stdin.Fd())}
+	if !term.IsTerminal(k.fd) {
+		k.lines = bufio.NewReader(os.Stdin)
+		return k
+	}
+
+	k.raw = true
+	return k
+}
+
+// ReadKey returns the name of the next key pressed, see keyName.
+func (k *keyReader) ReadKey() (key string, err error) {
+	if !k.raw {
+		line, err := k.lines.ReadString('\n')
+		line = strings.TrimSpace(line)
+		if line == "" && err == nil {
+			return "enter", nil
+		}
+		return line, err
+	}
+
+	k.mu.Lock()
+	k.state, err = term.MakeRaw(k.fd)
+	k.mu.Unlock()
+	if err != nil {
+		return "", err
+	}
+	defer k.Restore()
+
+	buf := make([]byte, 16)
+	n, err := os.Stdin.Read(buf)
+	if err != nil {
+		return "", err
+	}
+	return keyName(buf[:n]), nil
+}
+
+// Restore puts the terminal back in the mode it was in before ReadKey.
+func (k *keyReader) Restore() {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	if k.state != nil {
+		term.Restore(k.fd, k.state)
+		k.state = nil
+	}
+}
+
+func (k *keyReader) Close() {
+	k.Restore()
+}
@@ -0,0 +1,132 @@
+package readerUtil
+
+import (
+	"encoding/json"
+	"fmt"
+	"manga-cli/internals/config"
+	"maps"
+	"slices"
+)
+
+const (
+	ActionNext  = "next"
+	ActionPrev  = "prev"
+	ActionFirst = "first"
+	ActionLast  = "last"
+	ActionQuit  = "quit"
+
+	ActionNextChapter = "next-chapter"
+	ActionPrevChapter = "prev-chapter"
+	ActionShift       = "shift"
+
+	ActionZoomIn   = "zoom-in"
+	ActionZoomOut  = "zoom-out"
+	ActionFit      = "fit"
+	ActionPanUp    = "pan-up"
+	ActionPanDown  = "pan-down"
+	ActionPanLeft  = "pan-left"
+	ActionPanRight = "pan-right"
+)
+
+var defaultBindings = map[string][]string{
+	ActionNext:  {"n", "space", "right", "down", "l", "j", "pgdown"},
+	ActionPrev:  {"p", "backspace", "left", "up", "h", "k", "pgup"},
+	ActionFirst: {"g", "home"},
+	ActionLast:  {"G", "end"},
+	ActionQuit:  {"q", "esc", "ctrl-c"},
+
+	ActionNextChapter: {"]", "N"},
+	ActionPrevChapter: {"[", "P"},
+	ActionShift:       {"o"},
+
+	ActionZoomIn:   {"+", "="},
+	ActionZoomOut:  {"-"},
+	ActionFit:      {"f"},
+	ActionPanUp:    {"K"},
+	ActionPanDown:  {"J"},
+	ActionPanLeft:  {"H"},
+	ActionPanRight: {"L"},
+}
+
+// LoadBindings maps key names to reader actions. The "keys" config option
+// replaces the keys of any action it lists, e.g. {"next": ["n", "d"]}. A key
+// it assigns is taken away from the default keys of other actions, and
+// assigning one key to two actions is an error.
+func LoadBindings() (map[string]string, error) {
+	bindings := map[string]string{}
+	custom := map[string][]string{}
+
+	if val, err := config.GetConfigOption("keys"); err == nil && val != nil && val != "" {
+		data, ok := val.(string)
+		if !ok {
+			raw, err := json.Marshal(val)
+			if err != nil {
+				return nil, err
+			}
+			data = string(raw)
+		}
+
+		if err := json.Unmarshal([]byte(data), &custom); err != nil {
+			return nil, fmt.Errorf("invalid keys config: %w", err)
+		}
+		for _, action := range slices.Sorted(maps.Keys(custom)) {
+			keys := custom[action]
+			if _, ok := defaultBindings[action]; !ok {
+				return nil, fmt.Errorf("invalid keys config: unknown action '%s'", action)
+			}
+			for _, key := range keys {
+				if other, ok := bindings[key]; ok && other != action {
+					return nil, fmt.Errorf("invalid keys config: '%s' is bound to both %s and %s", key, other, action)
+				}
+				bindings[key] = action
+			}
+		}
+	}
+
+	for action, keys := range defaultBindings {
+		if _, ok := custom[action]; ok {
+			continue
+		}
+		for _, key := range keys {
+			if _, ok := bindings[key]; !ok {
+				bindings[key] = action
+			}
+		}
+	}
+	return bindings, nil
+}
+
+// keyName turns the bytes of one key press into the names used by bindings:
+// the character itself, or space, enter, esc, backspace, ctrl-c, the arrow
+// keys, home, end, pgup and pgdown.
+func keyName(b []byte) string {
+	switch string(b) {
+	case " ":
+		return "space"
+	case "\r", "\n":
+		return "enter"
+	case "\x1b":
+		return "esc"
+	case "\x7f", "\x08":
+		return "backspace"
+	case "\x03":
+		return "ctrl-c"
+	case "\x1b[A", "\x1bOA":
+		return "up"
+	case "\x1b[B", "\x1bOB":
+		return "down"
+	case "\x1b[C", "\x1bOC":
+		return "right"
+	case "\x1b[D", "\x1bOD":
+		return "left"
+	case "\x1b[H", "\x1bOH", "\x1b[1~", "\x1b[7~":
+		return "home"
+	case "\x1b[F", "\x1bOF", "\x1b[4~", "\x1b[8~":
+		return "end"
+	case "\x1b[5~":
+		return "pgup"
+	case "\x1b[6~":
+		return "pgdown"
+	}
+	return string(b)
+}
@@ -1,82 +1,403 @@
 package readerUtil
 
 import (
-	"bufio"
+	"context"
 	"fmt"
+	"image"
+	"io"
 	"manga-cli/internals/config"
+	"manga-cli/internals/history"
+	"manga-cli/internals/library"
 	"manga-cli/internals/utils"
+	"math"
 	"os"
-	"os/exec"
 	"path/filepath"
-	"sort"
+	"slices"
 	"strconv"
 	"strings"
 )
 
 var viewerCmd string = "viu"
 
+var viewer ViewerProfile
+
+// builtin is set when pages are drawn by the built-in renderer instead of
+// an external viewer.
+var builtin Renderer
+
+// lastPage opens a chapter at its final page.
+const lastPage = math.MaxInt32
+
+// Chapter is a chapter to read: a local folder or archive at Path, or a
+// chapter streamed from MangaDex by ID.
+type Chapter struct {
+	Title  string
+	Number string
+	Path   string
+	ID     string
+}
+
+// LocalChapter describes the chapter folder or archive at path. Chapters
+// outside the library have no title and are not recorded in the history.
+func LocalChapter(path string) Chapter {
+	title, number := ChapterOf(path)
+	return Chapter{Title: title, Number: number, Path: path}
+}
+
+// Options control a reading session. Page is the 1-based page to open at.
+// Fetch, when set, is used to get the next or previous chapter when the
+// library does not have it yet. Context and DataSaver apply to streamed
+// chapters. Mode is one of the reading modes, page by default. Direction
+// and Cover lay out spreads: right to left or left to right, and whether the
+// first page is a cover shown on its own. Fit is how single pages are sized
+// to the screen. A Width or Height of zero uses the terminal size.
+type Options struct {
+	Width     int
+	Height    int
+	Page      int
+	Mode      string
+	Direction string
+	Cover     bool
+	Fit       string
+	Fetch     func(title, chapter string, forward bool) (Chapter, error)
+	Context   context.Context
+	DataSaver bool
+}
+
 func StartReader(path string, width int, height int) error {
+	return Open(path, Options{Width: width, Height: height})
+}
+
+// Open reads the chapter folder or archive at path, see Read.
+func Open(path string, opts Options) error {
+	return Read(LocalChapter(path), opts)
+}
+
+// Read shows a chapter and records the reading position in the history as
+// pages are turned. Moving past the last or first page continues with the
+// adjacent chapter.
+func Read(ch Chapter, opts Options) error {
+	if opts.Context == nil {
+		opts.Context = context.Background()
+	}
 
 	viewerVal, err := config.GetConfigOption("viewer")
 	if err == nil && viewerVal != nil {
 		viewerCmd = fmt.Sprintf("%v", viewerVal)
 	}
 
-	if !isCommandAvailable(viewerCmd) {
-		v, err := promptViewer()
-		if err != nil || v == "" {
-			return fmt.Errorf("no valid image viewer configured, aborting")
+	viewer, err = ResolveViewer(viewerCmd)
+	if err != nil {
+		return err
+	}
+
+	builtin = nil
+	if viewerCmd == "builtin" || !viewer.Available() {
+		protocol := ProtocolAuto
+		if val, err := config.GetConfigOption("protocol"); err == nil && val != nil {
+			protocol = fmt.Sprintf("%v", val)
+		}
+		builtin, err = NewRenderer(protocol)
+		if err != nil {
+			return err
+		}
+	}
+
+	keys := newKeyReader()
+	defer keys.Close()
+	stop := exitOnSignal(keys)
+	defer stop()
+
+	if builtin == nil && viewer.Chapter {
+		pages, err := openChapter(ch, opts)
+		if err != nil {
+			return err
+		}
+		defer pages.Close()
+
+		if s, ok := pages.(*streamSource); ok {
+			s.keep = 0
+		}
+		if err := viewChapter(pages); err != nil {
+			return err
+		}
+		if ch.Title == "" {
+			return nil
 		}
-		viewerCmd = v
-		_ = config.SetConfigOption("viewer", viewerCmd)
+		return history.MarkRead(ch.Title, ch.Number)
 	}
 
-	files, err := os.ReadDir(path)
+	bindings, err := LoadBindings()
 	if err != nil {
 		return err
 	}
+	if opts.Mode == ModeSpread && opts.Direction == DirectionRTL {
+		// Pages of a right to left spread advance to the left.
+		bindings["left"], bindings["right"] = bindings["right"], bindings["left"]
+	}
 
-	var images []string
-	for _, f := range files {
-		if !f.IsDir() && isImageFile(f.Name()) {
-			images = append(images, filepath.Join(path, f.Name()))
+	page := opts.Page
+	for {
+		next, nextPage, err := readChapter(ch, page, opts, keys, bindings)
+		if err != nil || next == nil {
+			return err
 		}
+		ch, page = *next, nextPage
 	}
+}
 
-	sort.Strings(images)
+func openChapter(ch Chapter, opts Options) (PageSource, error) {
+	if ch.Path == "" {
+		return OpenStream(opts.Context, ch.ID, opts.DataSaver)
+	}
+	return OpenSource(ch.Path)
+}
 
-	if len(images) == 0 {
-		return fmt.Errorf("no images found in folder: %s ", path)
+// readChapter shows one chapter until the reader quits or moves to another
+// chapter, and returns the chapter and page to continue with, or nil to
+// stop.
+func readChapter(ch Chapter, page int, opts Options, keys *keyReader, bindings map[string]string) (*Chapter, int, error) {
+	source, err := openChapter(ch, opts)
+	if err != nil {
+		return nil, 0, err
+	}
+	pages, err := newPageCache(source)
+	if err != nil {
+		source.Close()
+		return nil, 0, err
 	}
+	defer pages.Close()
 
-	reader := bufio.NewReader(os.Stdin)
+	mangaTitle, chapterNo := ch.Title, ch.Number
+	status := ""
 
-	i := 0
+	v := newView(pages, opts, page)
 	for {
 		utils.ClearTerminal()
 
-		fmt.Printf("Page %d / %d \n", i+1, len(images))
-		if err := renderImage(images[i], width, height); err != nil {
+		fmt.Printf("Chapter %s  Page %d / %d %s\n", chapterNo, v.Page()+1, pages.Len(), v.Info())
+		if err := v.Draw(); err != nil {
 			fmt.Println("Error rendering panel:", err)
 		}
 
-		fmt.Print("[n] next  [p] prev  [q] quit: ")
-		input, _ := reader.ReadString('\n')
-		input = strings.TrimSpace(input)
+		if mangaTitle != "" {
+			_ = history.SavePosition(mangaTitle, chapterNo, v.Page()+1, v.AtEnd())
+		}
+
+		if status != "" {
+			fmt.Println(status)
+			status = ""
+		}
 
-		switch input {
-		case "n":
-			if i < len(images)-1 {
-				i++
+		action, err := waitForMove(keys, bindings, v)
+		switch {
+		case err != nil && err != io.EOF:
+			return nil, 0, err
+		case err != nil || action == ActionQuit:
+			// Stdin running out ends the session like quitting does.
+			return nil, 0, nil
+		case action == ActionNextChapter || action == ActionPrevChapter:
+			forward := action == ActionNextChapter
+			adjacent, err := adjacentChapter(ch, forward, opts.Fetch)
+			if err != nil {
+				status = err.Error()
+				continue
 			}
-		case "p":
-			if i > 0 {
-				i--
+			if forward {
+				return &adjacent, 1, nil
 			}
-		case "q":
-			return nil
+			return &adjacent, lastPage, nil
+		}
+	}
+}
+
+// adjacentChapter returns the chapter after (or before) ch. A downloaded
+// neighbour in the same folder is used when no chapter can be missing in
+// between; otherwise, with fetch set, the neighbour on MangaDex is looked up
+// so a missing chapter is fetched rather than skipped.
+func adjacentChapter(ch Chapter, forward bool, fetch func(title, chapter string, forward bool) (Chapter, error)) (Chapter, error) {
+	which := "next"
+	if !forward {
+		which = "previous"
+	}
+
+	local, err := localNeighbour(ch, forward)
+	if err != nil {
+		return Chapter{}, err
+	}
+	if local != nil && (fetch == nil || ch.Title == "" || follows(ch.Number, local.Number, forward)) {
+		return *local, nil
+	}
+
+	if fetch != nil && ch.Title != "" {
+		next, err := fetch(ch.Title, ch.Number, forward)
+		if err == nil {
+			return next, nil
+		}
+		if local != nil {
+			return *local, nil
+		}
+		return Chapter{}, fmt.Errorf("no %s chapter: %v", which, err)
+	}
+	return Chapter{}, fmt.Errorf("no %s chapter downloaded", which)
+}
+
+// localNeighbour returns the chapter after (or before) ch in its folder, or
+// nil when there is none.
+func localNeighbour(ch Chapter, forward bool) (*Chapter, error) {
+	if ch.Path == "" {
+		return nil, nil
+	}
+	dir := filepath.Dir(ch.Path)
+	chapters, err := library.ChaptersIn(dir)
+	if err != nil {
+		return nil, err
+	}
+	i := slices.Index(chapters, ch.Number)
+	if i < 0 {
+		return nil, nil
+	}
+	j := i + 1
+	if !forward {
+		j = i - 1
+	}
+	if j < 0 || j >= len(chapters) {
+		return nil, nil
+	}
+	path, err := utils.FindChapterPath(dir, chapters[j])
+	if err != nil {
+		return nil, err
+	}
+	next := LocalChapter(path)
+	return &next, nil
+}
+
+// follows reports whether chapter b comes right after (or before) a, so no
+// whole chapter number lies between them. Chapters that are not numbers
+// always follow each other.
+func follows(a, b string, forward bool) bool {
+	x, aok := library.ChapterNumber(a)
+	y, bok := library.ChapterNumber(b)
+	if !aok || !bok {
+		return true
+	}
+	if forward {
+		return y <= math.Floor(x)+1
+	}
+	return y >= math.Ceil(x)-1
+}
+
+const readerPrompt = "[n/p] page  [N/P] chapter  [g/G] first/last  [number] go to  [f/+/-] fit/zoom  [q] quit: "
+
+// statusLines is how many terminal lines the reader uses besides the page:
+// the chapter header, a status message and the prompt.
+const statusLines = 3
+
+// viewBox returns the cell box pages are drawn in. A width or height of
+// zero takes the terminal size, leaving room for the status lines.
+func viewBox(width, height int) (int, int) {
+	size, err := utils.TerminalSize()
+	if err != nil || size.Cols == 0 || size.Rows <= statusLines {
+		size = utils.TermSize{Cols: 80, Rows: 24}
+	}
+	if width <= 0 {
+		width = size.Cols
+	}
+	if height <= 0 {
+		height = size.Rows - statusLines
+	}
+	return width, height
+}
+
+// actionGoTo is returned by readAction for a typed page number.
+const actionGoTo = "goto"
+
+// waitForMove reads keys until one of them moves the view, and returns
+// the chapter or quit action when that is what was asked for. Moving past
+// the last or first page asks for the adjacent chapter.
+func waitForMove(keys *keyReader, bindings map[string]string, v view) (string, error) {
+	fmt.Print(readerPrompt)
+
+	for {
+		action, page, err := readAction(keys, bindings)
+		if err != nil {
+			return ActionQuit, err
+		}
+
+		switch action {
+		case ActionNextChapter, ActionPrevChapter, ActionQuit:
+			return action, nil
+		}
+		if v.Move(action, page) {
+			return "", nil
+		}
+		switch {
+		case action == ActionNext && v.AtEnd():
+			return ActionNextChapter, nil
+		case action == ActionPrev && v.AtStart():
+			return ActionPrevChapter, nil
+		}
+	}
+}
+
+// readAction reads keys until one of them is bound to an action. Digits
+// build up a page number that is returned with actionGoTo once enter, g or
+// G is pressed.
+func readAction(keys *keyReader, bindings map[string]string) (string, int, error) {
+	pending := ""
+	for {
+		key, err := keys.ReadKey()
+		if err != nil {
+			return ActionQuit, 0, err
+		}
+
+		if isNumber(key) {
+			if !keys.raw {
+				n, _ := strconv.Atoi(key)
+				return actionGoTo, n, nil
+			}
+			pending += key
+			fmt.Printf("\r\x1b[KGo to page: %s", pending)
+			continue
+		}
+
+		if pending != "" {
+			switch {
+			case key == "enter" || bindings[key] == ActionFirst || bindings[key] == ActionLast:
+				n, _ := strconv.Atoi(pending)
+				return actionGoTo, n, nil
+			case key == "backspace":
+				pending = pending[:len(pending)-1]
+				fmt.Printf("\r\x1b[KGo to page: %s", pending)
+				continue
+			}
+			pending = ""
+			fmt.Print("\r\x1b[K" + readerPrompt)
+			if key == "esc" {
+				continue
+			}
+		}
+
+		if action, ok := bindings[key]; ok {
+			return action, 0, nil
+		}
+	}
+}
+
+func isNumber(s string) bool {
+	if s == "" {
+		return false
+	}
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
 		}
 	}
+	return true
+}
+
+func clampPage(page, total int) int {
+	return min(max(page, 1), total) - 1
 }
 
 func isImageFile(name string) bool {
@@ -84,25 +405,41 @@ func isImageFile(name string) bool {
 	return ext == ".jpg" || ext == ".jpeg" || ext == ".png" || ext == ".webp"
 }
 
-func renderImage(path string, width int, height int) error {
-	cmd := exec.Command(viewerCmd, "-w", strconv.Itoa(width), "-h", strconv.Itoa(height), path)
-	cmd.Stdout = os.Stdout
-	cmd.Stderr = os.Stderr
-	return cmd.Run()
+func renderPage(pages *pageCache, i int, width int, height int) error {
+	p := pages.Show(i, pageSize{width: width, height: height})
+	if p.err != nil {
+		return p.err
+	}
+	if builtin != nil {
+		_, err := os.Stdout.Write(p.out)
+		return err
+	}
+	return viewer.Cmd(p.path, []string{p.path}, filepath.Dir(p.source), width, height).Run()
 }
 
-func isCommandAvailable(name string) bool {
-	_, err := exec.LookPath(name)
-	return err == nil
+// showImage draws an image composed from several pages. External viewers
+// get it as a file in dir.
+func showImage(img image.Image, dir string, width, height int) error {
+	if builtin != nil {
+		return builtin.Draw(os.Stdout, img, width, height)
+	}
+	path := filepath.Join(dir, "view.png")
+	if err := writePNG(path, img); err != nil {
+		return err
+	}
+	return viewer.Cmd(path, []string{path}, dir, width, height).Run()
 }
 
-func promptViewer() (string, error) {
-	fmt.Print("No image viewer found. Please enter your preferred image viewer command (e.g., viu, chafa): ")
-	reader := bufio.NewReader(os.Stdin)
-	viewer, err := reader.ReadString('\n')
-	if err != nil {
-		return "", err
+// viewChapter hands every page to a viewer that shows the whole chapter and
+// waits for it to close.
+func viewChapter(pages PageSource) error {
+	var paths []string
+	for i := 0; i < pages.Len(); i++ {
+		p, err := pages.Page(i)
+		if err != nil {
+			return err
+		}
+		paths = append(paths, p)
 	}
-	viewer = strings.TrimSpace(viewer)
-	return viewer, nil
+	return viewer.Cmd(paths[0], paths, filepath.Dir(paths[0]), 0, 0).Run()
 }
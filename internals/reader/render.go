@@ -0,0 +1,183 @@
+package readerUtil
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"image"
+	"image/png"
+	"io"
+	"manga-cli/internals/imageproc"
+	"manga-cli/internals/utils"
+	"math"
+	"os"
+	"strings"
+)
+
+const (
+	ProtocolAuto   = "auto"
+	ProtocolKitty  = "kitty"
+	ProtocolSixel  = "sixel"
+	ProtocolITerm  = "iterm"
+	ProtocolBlocks = "blocks"
+)
+
+// Renderer draws an image into a box of cols x rows terminal cells,
+// keeping its aspect ratio.
+type Renderer interface {
+	Draw(w io.Writer, img image.Image, cols, rows int) error
+}
+
+// NewRenderer returns the built-in renderer for a graphics protocol, or the
+// best one for the current terminal when protocol is "auto" or empty.
+func NewRenderer(protocol string) (Renderer, error) {
+	if protocol == "" || protocol == ProtocolAuto {
+		protocol = DetectProtocol()
+	}
+	switch protocol {
+	case ProtocolKitty:
+		return kittyRenderer{}, nil
+	case ProtocolSixel:
+		return sixelRenderer{}, nil
+	case ProtocolITerm:
+		return itermRenderer{}, nil
+	case ProtocolBlocks:
+		return blockRenderer{}, nil
+	}
+	return nil, fmt.Errorf("unknown protocol '%s' (use auto, kitty, sixel, iterm or blocks)", protocol)
+}
+
+// DetectProtocol guesses the graphics protocol of the terminal from its
+// environment. Inside tmux or screen the graphics escapes do not reach the
+// outer terminal, so half-blocks are used there.
+func DetectProtocol() string {
+	term := os.Getenv("TERM")
+	program := os.Getenv("TERM_PROGRAM")
+
+	switch {
+	case os.Getenv("TMUX") != "" || strings.HasPrefix(term, "screen"):
+		return ProtocolBlocks
+	case os.Getenv("KITTY_WINDOW_ID") != "" || strings.Contains(term, "kitty") || strings.Contains(term, "ghostty") || program == "ghostty":
+		return ProtocolKitty
+	case program == "iTerm.app" || program == "WezTerm" || os.Getenv("LC_TERMINAL") == "iTerm2":
+		return ProtocolITerm
+	case strings.Contains(term, "sixel") || strings.HasPrefix(term, "foot") || strings.Contains(term, "mlterm") || strings.HasPrefix(term, "contour") || program == "mintty":
+		return ProtocolSixel
+	}
+	return ProtocolBlocks
+}
+
+// fitCells returns how many cells an image of size b covers when scaled to
+// fit in cols x rows cells.
+func fitCells(b image.Rectangle, cols, rows int) (int, int) {
+	size, _ := utils.TerminalSize()
+	cellW, cellH := size.CellSize()
+
+	scale := math.Min(float64(cols*cellW)/float64(b.Dx()), float64(rows*cellH)/float64(b.Dy()))
+	c := int(math.Round(float64(b.Dx()) * scale / float64(cellW)))
+	r := int(math.Round(float64(b.Dy()) * scale / float64(cellH)))
+	return max(c, 1), max(r, 1)
+}
+
+func encodePNG(img image.Image) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+type kittyRenderer struct{}
+
+// Draw sends the image as PNG in 4096 byte chunks and lets the terminal
+// scale it to the computed cell box. Earlier placements are deleted first
+// since clearing the screen does not remove them.
+func (kittyRenderer) Draw(w io.Writer, img image.Image, cols, rows int) error {
+	data, err := encodePNG(img)
+	if err != nil {
+		return err
+	}
+	c, r := fitCells(img.Bounds(), cols, rows)
+	payload := base64.StdEncoding.EncodeToString(data)
+
+	bw := bufio.NewWriter(w)
+	fmt.Fprint(bw, "\x1b_Ga=d,q=2\x1b\\")
+	for first := true; len(payload) > 0; first = false {
+		n := min(len(payload), 4096)
+		more := 0
+		if n < len(payload) {
+			more = 1
+		}
+		if first {
+			fmt.Fprintf(bw, "\x1b_Ga=T,f=100,q=2,c=%d,r=%d,m=%d;%s\x1b\\", c, r, more, payload[:n])
+		} else {
+			fmt.Fprintf(bw, "\x1b_Gm=%d;%s\x1b\\", more, payload[:n])
+		}
+		payload = payload[n:]
+	}
+	fmt.Fprintln(bw)
+	return bw.Flush()
+}
+
+type itermRenderer struct{}
+
+func (itermRenderer) Draw(w io.Writer, img image.Image, cols, rows int) error {
+	data, err := encodePNG(img)
+	if err != nil {
+		return err
+	}
+	c, r := fitCells(img.Bounds(), cols, rows)
+	_, err = fmt.Fprintf(w, "\x1b]1337;File=inline=1;size=%d;width=%d;height=%d;preserveAspectRatio=1:%s\a\n",
+		len(data), c, r, base64.StdEncoding.EncodeToString(data))
+	return err
+}
+
+type sixelRenderer struct{}
+
+func (sixelRenderer) Draw(w io.Writer, img image.Image, cols, rows int) error {
+	size, _ := utils.TerminalSize()
+	cellW, cellH := size.CellSize()
+	c, r := fitCells(img.Bounds(), cols, rows)
+
+	scaled := imageproc.Resize(img, c*cellW, r*cellH)
+	bw := bufio.NewWriter(w)
+	encodeSixel(bw, scaled)
+	fmt.Fprintln(bw)
+	return bw.Flush()
+}
+
+type blockRenderer struct{}
+
+// Draw prints two pixels per cell with the upper half block, the top pixel
+// as foreground and the bottom one as background colour.
+func (blockRenderer) Draw(w io.Writer, img image.Image, cols, rows int) error {
+	b := img.Bounds()
+	scale := math.Min(float64(cols)/float64(b.Dx()), float64(rows*2)/float64(b.Dy()))
+	width := max(int(float64(b.Dx())*scale), 1)
+	height := max(int(float64(b.Dy())*scale), 1)
+	scaled := imageproc.Resize(img, width, height)
+
+	bw := bufio.NewWriter(w)
+	for y := 0; y < height; y += 2 {
+		for x := 0; x < width; x++ {
+			tr, tg, tb := rgb8(scaled, x, y)
+			fmt.Fprintf(bw, "\x1b[38;2;%d;%d;%dm", tr, tg, tb)
+			if y+1 < height {
+				br, bg, bb := rgb8(scaled, x, y+1)
+				fmt.Fprintf(bw, "\x1b[48;2;%d;%d;%dm", br, bg, bb)
+			} else {
+				fmt.Fprint(bw, "\x1b[49m")
+			}
+			fmt.Fprint(bw, "▀")
+		}
+		fmt.Fprint(bw, "\x1b[0m\n")
+	}
+	return bw.Flush()
+}
+
+func rgb8(img image.Image, x, y int) (uint8, uint8, uint8) {
+	b := img.Bounds()
+	r, g, bl, _ := img.At(b.Min.X+x, b.Min.Y+y).RGBA()
+	return uint8(r >> 8), uint8(g >> 8), uint8(bl >> 8)
+}
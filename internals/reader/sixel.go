@@ -0,0 +1,70 @@
+package readerUtil
+
+import (
+	"fmt"
+	"image"
+	"image/color/palette"
+	"image/draw"
+	"io"
+)
+
+// encodeSixel writes img as a DEC sixel sequence, dithered to the 256 colour
+// Plan 9 palette.
+func encodeSixel(w io.Writer, img image.Image) {
+	b := img.Bounds()
+	width, height := b.Dx(), b.Dy()
+	pal := image.NewPaletted(image.Rect(0, 0, width, height), palette.Plan9)
+	draw.FloydSteinberg.Draw(pal, pal.Bounds(), img, b.Min)
+
+	fmt.Fprintf(w, "\x1bPq\"1;1;%d;%d", width, height)
+	for i, c := range pal.Palette {
+		r, g, bl, _ := c.RGBA()
+		fmt.Fprintf(w, "#%d;2;%d;%d;%d", i, r*100/0xffff, g*100/0xffff, bl*100/0xffff)
+	}
+
+	bands := make([][]byte, len(pal.Palette))
+	for y0 := 0; y0 < height; y0 += 6 {
+		var used []int
+		for k := 0; k < 6 && y0+k < height; k++ {
+			row := pal.Pix[(y0+k)*pal.Stride:]
+			for x := 0; x < width; x++ {
+				idx := row[x]
+				if bands[idx] == nil {
+					bands[idx] = make([]byte, width)
+					used = append(used, int(idx))
+				}
+				bands[idx][x] |= 1 << k
+			}
+		}
+
+		for n, idx := range used {
+			if n > 0 {
+				fmt.Fprint(w, "$")
+			}
+			fmt.Fprintf(w, "#%d", idx)
+			writeSixelRow(w, bands[idx])
+			bands[idx] = nil
+		}
+		fmt.Fprint(w, "-")
+	}
+	fmt.Fprint(w, "\x1b\\")
+}
+
+// writeSixelRow writes one colour of a band with run-length encoding.
+func writeSixelRow(w io.Writer, bits []byte) {
+	for x := 0; x < len(bits); {
+		run := 1
+		for x+run < len(bits) && bits[x+run] == bits[x] {
+			run++
+		}
+		ch := bits[x] + 63
+		if run > 3 {
+			fmt.Fprintf(w, "!%d%c", run, ch)
+		} else {
+			for i := 0; i < run; i++ {
+				fmt.Fprintf(w, "%c", ch)
+			}
+		}
+		x += run
+	}
+}
@@ -0,0 +1,142 @@
+package readerUtil
+
+import (
+	"fmt"
+	"manga-cli/internals/archive"
+	"manga-cli/internals/utils"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// PageSource provides the pages of one chapter as local image files.
+type PageSource interface {
+	Len() int
+	Page(i int) (string, error)
+	Close() error
+}
+
+// focuser is implemented by sources that only keep the pages near the one
+// being read, such as streams. Focus moves that window to page i and widens
+// it to at least radius pages on each side.
+type focuser interface {
+	Focus(i, radius int)
+}
+
+// OpenSource opens a chapter folder or a .cbz/.zip/.cbr/.rar archive.
+func OpenSource(path string) (PageSource, error) {
+	if archive.IsArchive(path) {
+		return openArchiveSource(path)
+	}
+	return openDirSource(path)
+}
+
+// ChapterOf returns the manga title and chapter a source path belongs to.
+// The title is empty for paths outside the library, as the folder holding
+// them is not a manga.
+func ChapterOf(path string) (string, string) {
+	chapter := filepath.Base(path)
+	if archive.IsArchive(path) {
+		chapter = strings.TrimSuffix(chapter, filepath.Ext(chapter))
+	}
+	return libraryTitle(path), chapter
+}
+
+// libraryTitle returns the manga folder name of a chapter path laid out as
+// <library>/<title>/<chapter>, or an empty string.
+func libraryTitle(path string) string {
+	root, err := utils.GetOrCreateMangaCliDir()
+	if err != nil {
+		return ""
+	}
+	mangaDir := filepath.Dir(realPath(path))
+	if filepath.Dir(mangaDir) != realPath(root) {
+		return ""
+	}
+	return filepath.Base(mangaDir)
+}
+
+func realPath(path string) string {
+	if abs, err := filepath.Abs(path); err == nil {
+		path = abs
+	}
+	if real, err := filepath.EvalSymlinks(path); err == nil {
+		path = real
+	}
+	return path
+}
+
+type dirSource struct {
+	images []string
+}
+
+func openDirSource(path string) (*dirSource, error) {
+	files, err := os.ReadDir(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var images []string
+	for _, f := range files {
+		if !f.IsDir() && isImageFile(f.Name()) {
+			images = append(images, filepath.Join(path, f.Name()))
+		}
+	}
+	sort.Strings(images)
+
+	if len(images) == 0 {
+		return nil, fmt.Errorf("no images found in folder: %s ", path)
+	}
+	return &dirSource{images: images}, nil
+}
+
+func (s *dirSource) Len() int                   { return len(s.images) }
+func (s *dirSource) Page(i int) (string, error) { return s.images[i], nil }
+func (s *dirSource) Close() error               { return nil }
+
+// archiveSource extracts pages on first access into a temporary cache that
+// is removed when the source is closed.
+type archiveSource struct {
+	mu    sync.Mutex
+	ar    *archive.Reader
+	pages []string
+	cache string
+}
+
+func openArchiveSource(path string) (*archiveSource, error) {
+	ar, err := archive.Open(path)
+	if err != nil {
+		return nil, err
+	}
+
+	pages := archive.Images(ar.Names())
+	if len(pages) == 0 {
+		ar.Close()
+		return nil, fmt.Errorf("no images found in archive: %s", path)
+	}
+
+	cache, err := makeTempDir("manga-cli-*")
+	if err != nil {
+		ar.Close()
+		return nil, err
+	}
+	return &archiveSource{ar: ar, pages: pages, cache: cache}, nil
+}
+
+func (s *archiveSource) Len() int { return len(s.pages) }
+
+func (s *archiveSource) Page(i int) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.ar.Extract(s.pages[i], s.cache)
+}
+
+func (s *archiveSource) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	err := s.ar.Close()
+	removeTempDir(s.cache)
+	return err
+}
@@ -0,0 +1,209 @@
+package readerUtil
+
+import (
+	"image"
+	"image/draw"
+	"manga-cli/internals/imageproc"
+)
+
+// spreadView shows two pages side by side like an open book: the first
+// page of a pair on the right for right to left manga, on the left for
+// comics. With a cover the first page is shown on its own so the pairs line
+// up with the printed book. Pages that are wider than tall are already
+// spreads and are shown alone, as is a page whose partner is one.
+type spreadView struct {
+	pages         *pageCache
+	width, height int
+	rtl, cover    bool
+
+	// starts holds the first page of every spread laid out so far, in
+	// order. The layout starts at the page opened and grows in either
+	// direction as far as it is needed, so no page before it has to be
+	// fetched. s is the current spread.
+	starts []int
+	s      int
+	wide   map[int]bool
+}
+
+func newSpreadView(pages *pageCache, opts Options, page int) *spreadView {
+	width, height := viewBox(opts.Width, opts.Height)
+	v := &spreadView{
+		pages:  pages,
+		width:  width,
+		height: height,
+		rtl:    opts.Direction != DirectionLTR,
+		cover:  opts.Cover,
+		wide:   map[int]bool{},
+	}
+	v.relayout(clampPage(page, pages.Len()))
+	return v
+}
+
+// isWide reports whether page i is a spread on its own. The page is taken
+// from the cache, so a streamed page is fetched once and only by the cache.
+func (v *spreadView) isWide(i int) bool {
+	if wide, ok := v.wide[i]; ok {
+		return wide
+	}
+	p := v.pages.Show(i, v.size())
+	if p.err != nil {
+		return false
+	}
+	b := p.img.Bounds()
+	v.wide[i] = b.Dx() > b.Dy()
+	return v.wide[i]
+}
+
+// size is what pages are decoded at. Single pages use the same size as
+// pairs so both share the page cache.
+func (v *spreadView) size() pageSize {
+	return pageSize{width: v.width, height: v.height, decoded: true}
+}
+
+// span returns how many pages the spread starting at page i shows.
+func (v *spreadView) span(i int) int {
+	last := v.pages.Len() - 1
+	if i == last || (v.cover && i == 0) || v.isWide(i) || v.isWide(i+1) {
+		return 1
+	}
+	return 2
+}
+
+// spanBefore returns how many pages the spread ending at page i shows.
+func (v *spreadView) spanBefore(i int) int {
+	if i == 0 || (v.cover && i == 1) || v.isWide(i) || v.isWide(i-1) {
+		return 1
+	}
+	return 2
+}
+
+// layoutTo lays out spreads after the last one until the one holding page
+// i, and returns its index. Pages before the layout are not reached this
+// way; see relayout.
+func (v *spreadView) layoutTo(i int) int {
+	for k, start := range v.starts {
+		if start <= i && i < start+v.span(start) {
+			return k
+		}
+	}
+	for {
+		last := v.starts[len(v.starts)-1]
+		next := last + v.span(last)
+		if next >= v.pages.Len() {
+			return len(v.starts) - 1
+		}
+		v.starts = append(v.starts, next)
+		if i < next+v.span(next) {
+			return len(v.starts) - 1
+		}
+	}
+}
+
+// layoutBefore lays out the spread before the first one, and reports
+// whether there is one.
+func (v *spreadView) layoutBefore() bool {
+	first := v.starts[0]
+	if first == 0 {
+		return false
+	}
+	start := first - v.spanBefore(first-1)
+	v.starts = append([]int{start}, v.starts...)
+	v.s++
+	return true
+}
+
+// relayout lays the chapter out again from page i, e.g. after the cover
+// setting changed or a jump, and moves to the spread starting there.
+func (v *spreadView) relayout(i int) {
+	v.starts = []int{i}
+	v.s = 0
+}
+
+func (v *spreadView) Draw() error {
+	size := v.size()
+	start := v.starts[v.s]
+	first := v.pages.Show(start, size)
+	if first.err != nil {
+		return first.err
+	}
+	if v.span(start) == 1 {
+		return showImage(first.img, v.pages.dir, v.width, v.height)
+	}
+	second := v.pages.Show(start+1, size)
+	if second.err != nil {
+		return second.err
+	}
+
+	left, right := first.img, second.img
+	if v.rtl {
+		left, right = right, left
+	}
+
+	// Both pages are shown at the height of the taller one.
+	height := max(left.Bounds().Dy(), right.Bounds().Dy())
+	left, right = scaleToHeight(left, height), scaleToHeight(right, height)
+
+	lw := left.Bounds().Dx()
+	canvas := image.NewRGBA(image.Rect(0, 0, lw+right.Bounds().Dx(), height))
+	draw.Draw(canvas, image.Rect(0, 0, lw, height), left, left.Bounds().Min, draw.Src)
+	draw.Draw(canvas, image.Rect(lw, 0, canvas.Bounds().Dx(), height), right, right.Bounds().Min, draw.Src)
+	return showImage(canvas, v.pages.dir, v.width, v.height)
+}
+
+func scaleToHeight(img image.Image, height int) image.Image {
+	b := img.Bounds()
+	if b.Dy() == height {
+		return img
+	}
+	return imageproc.Resize(img, max(b.Dx()*height/b.Dy(), 1), height)
+}
+
+func (v *spreadView) Page() int     { return v.starts[v.s] }
+func (v *spreadView) AtStart() bool { return v.starts[v.s] == 0 }
+func (v *spreadView) Info() string  { return "" }
+
+func (v *spreadView) AtEnd() bool {
+	start := v.starts[v.s]
+	return start+v.span(start) >= v.pages.Len()
+}
+
+func (v *spreadView) Move(action string, page int) bool {
+	s := v.s
+	switch action {
+	case ActionNext:
+		if !v.AtEnd() {
+			start := v.starts[v.s]
+			s = v.layoutTo(start + v.span(start))
+		}
+	case ActionPrev:
+		if v.s == 0 && !v.layoutBefore() {
+			return false
+		}
+		s = v.s - 1
+	case ActionFirst:
+		if v.starts[v.s] == 0 {
+			return false
+		}
+		v.relayout(0)
+		return true
+	case ActionLast:
+		last := v.pages.Len() - 1
+		if v.starts[v.s]+v.span(v.starts[v.s]) > last {
+			return false
+		}
+		v.relayout(last - v.spanBefore(last) + 1)
+		return true
+	case actionGoTo:
+		v.relayout(clampPage(page, v.pages.Len()))
+		return true
+	case ActionShift:
+		v.cover = !v.cover
+		v.relayout(v.starts[v.s])
+		return true
+	}
+	if s == v.s {
+		return false
+	}
+	v.s = s
+	return true
+}
@@ -0,0 +1,149 @@
+package readerUtil
+
+import (
+	"context"
+	"fmt"
+	"manga-cli/internals/api"
+	"manga-cli/internals/downloader"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+const (
+	streamPrefetch = 3
+	streamKeep     = 5
+)
+
+// streamSource reads a chapter straight from the MangaDex at-home server.
+// Pages are fetched into a temporary folder when first needed, the next few
+// are prefetched in the background, and pages far from the one in focus are
+// deleted so the cache stays small. Nothing is written to the library.
+type streamSource struct {
+	ctx    context.Context
+	cancel context.CancelFunc
+	urls   []string
+	cache  string
+	keep   int
+
+	mu    sync.Mutex
+	wg    sync.WaitGroup
+	pages map[int]*streamPage
+}
+
+type streamPage struct {
+	done chan struct{}
+	path string
+	err  error
+}
+
+func OpenStream(ctx context.Context, chapterID string, useDataSaver bool) (PageSource, error) {
+	atHome, err := api.GetAtHomeServer(ctx, chapterID)
+	if err != nil {
+		return nil, err
+	}
+
+	files := atHome.Chapter.Data
+	if useDataSaver {
+		files = atHome.Chapter.DataSaver
+	}
+	if len(files) == 0 {
+		return nil, fmt.Errorf("chapter has no pages on MangaDex")
+	}
+
+	cache, err := makeTempDir("manga-cli-stream-*")
+	if err != nil {
+		return nil, err
+	}
+
+	s := &streamSource{cache: cache, keep: streamKeep, pages: map[int]*streamPage{}}
+	s.ctx, s.cancel = context.WithCancel(ctx)
+	for _, f := range files {
+		s.urls = append(s.urls, downloader.PageURL(atHome.BaseURL, atHome.Chapter.Hash, f, useDataSaver))
+	}
+	return s, nil
+}
+
+func (s *streamSource) Len() int { return len(s.urls) }
+
+func (s *streamSource) Page(i int) (string, error) {
+	p := s.fetch(i)
+	for j := 1; j <= streamPrefetch && i+j < len(s.urls); j++ {
+		s.fetch(i + j)
+	}
+
+	select {
+	case <-p.done:
+	case <-s.ctx.Done():
+		return "", s.ctx.Err()
+	}
+	return p.path, p.err
+}
+
+// Focus deletes finished pages outside the window around page i. The window
+// spans keep pages on each side, widened to radius and the pages Page
+// prefetches beyond it, so pages a page cache is preparing stay on disk.
+// Only the page being shown moves the window, not the prefetches around it.
+func (s *streamSource) Focus(i, radius int) {
+	s.evict(i, max(s.keep, radius+streamPrefetch))
+}
+
+// fetch starts downloading page i unless it is cached or already on its
+// way. Failed pages are tried again.
+func (s *streamSource) fetch(i int) *streamPage {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if p, ok := s.pages[i]; ok {
+		select {
+		case <-p.done:
+			if p.err == nil {
+				return p
+			}
+		default:
+			return p
+		}
+	}
+
+	p := &streamPage{
+		done: make(chan struct{}),
+		path: filepath.Join(s.cache, fmt.Sprintf("%04d%s", i+1, filepath.Ext(s.urls[i]))),
+	}
+	s.pages[i] = p
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		p.err = downloader.FetchPage(s.ctx, s.urls[i], p.path)
+		close(p.done)
+	}()
+	return p
+}
+
+// evict deletes finished pages that are further than window pages behind or
+// ahead of page i. A keep of zero holds on to every page.
+func (s *streamSource) evict(i, window int) {
+	if s.keep == 0 {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for j, p := range s.pages {
+		if j >= i-window && j <= i+window {
+			continue
+		}
+		select {
+		case <-p.done:
+			os.Remove(p.path)
+			delete(s.pages, j)
+		default:
+		}
+	}
+}
+
+// Close stops pending downloads and removes the cache.
+func (s *streamSource) Close() error {
+	s.cancel()
+	s.wg.Wait()
+	return removeTempDir(s.cache)
+}
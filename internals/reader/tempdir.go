@@ -0,0 +1,50 @@
+package readerUtil
+
+import (
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+)
+
+// tempDirs holds the temporary folders of open chapters, so they can be
+// removed when the reader is interrupted or killed before closing them.
+var tempDirs sync.Map
+
+func makeTempDir(pattern string) (string, error) {
+	dir, err := os.MkdirTemp("", pattern)
+	if err == nil {
+		tempDirs.Store(dir, struct{}{})
+	}
+	return dir, err
+}
+
+func removeTempDir(dir string) error {
+	tempDirs.Delete(dir)
+	return os.RemoveAll(dir)
+}
+
+// exitOnSignal restores the terminal, removes the temporary folders and
+// exits when the reader is interrupted or killed. The returned func stops
+// watching for signals.
+func exitOnSignal(keys *keyReader) func() {
+	sig := make(chan os.Signal, 1)
+	done := make(chan struct{})
+	signal.Notify(sig, os.Interrupt, syscall.SIGTERM, syscall.SIGHUP)
+	go func() {
+		select {
+		case <-sig:
+			keys.Restore()
+			tempDirs.Range(func(dir, _ any) bool {
+				os.RemoveAll(dir.(string))
+				return true
+			})
+			os.Exit(1)
+		case <-done:
+		}
+	}()
+	return func() {
+		signal.Stop(sig)
+		close(done)
+	}
+}
@@ -0,0 +1,251 @@
+package readerUtil
+
+import (
+	"fmt"
+	"image"
+	"manga-cli/internals/imageproc"
+	"manga-cli/internals/utils"
+	"math"
+	"slices"
+)
+
+const (
+	ModeAuto    = "auto"
+	ModePage    = "page"
+	ModeWebtoon = "webtoon"
+	ModeSpread  = "spread"
+)
+
+const (
+	DirectionRTL = "rtl"
+	DirectionLTR = "ltr"
+)
+
+const (
+	FitScreen   = "fit-screen"
+	FitWidth    = "fit-width"
+	FitHeight   = "fit-height"
+	FitOriginal = "original"
+)
+
+// fits is the order the fit action cycles through.
+var fits = []string{FitScreen, FitWidth, FitHeight, FitOriginal}
+
+const (
+	zoomStep = 1.25
+	minZoom  = 0.25
+	maxZoom  = 8
+)
+
+// ParseMode checks a reading mode given on the command line or in the
+// config. An empty mode is auto.
+func ParseMode(mode string) (string, error) {
+	switch mode {
+	case "", ModeAuto:
+		return ModeAuto, nil
+	case ModePage, ModeWebtoon, ModeSpread:
+		return mode, nil
+	}
+	return "", fmt.Errorf("unknown reading mode '%s' (use auto, page, webtoon or spread)", mode)
+}
+
+// ParseDirection checks a reading direction. An empty direction is right
+// to left.
+func ParseDirection(direction string) (string, error) {
+	switch direction {
+	case "", DirectionRTL:
+		return DirectionRTL, nil
+	case DirectionLTR:
+		return direction, nil
+	}
+	return "", fmt.Errorf("unknown reading direction '%s' (use rtl or ltr)", direction)
+}
+
+// ParseFit checks a page fit. An empty fit is fit-screen.
+func ParseFit(fit string) (string, error) {
+	if fit == "" {
+		return FitScreen, nil
+	}
+	if slices.Contains(fits, fit) {
+		return fit, nil
+	}
+	return "", fmt.Errorf("unknown fit '%s' (use fit-screen, fit-width, fit-height or original)", fit)
+}
+
+// view lays the pages of a chapter out on screen for a reading mode and
+// keeps track of the position in it.
+type view interface {
+	// Draw shows the current position.
+	Draw() error
+	// Page returns the index of the page at the current position.
+	Page() int
+	// AtStart and AtEnd report whether the first or last page is shown.
+	AtStart() bool
+	AtEnd() bool
+	// Move applies a reader action and reports whether the position
+	// changed. page is the 1-based number given with actionGoTo.
+	Move(action string, page int) bool
+	// Info describes view settings worth showing next to the page number.
+	Info() string
+}
+
+// newView opens a chapter in the reading mode of opts at the 1-based page.
+func newView(pages *pageCache, opts Options, page int) view {
+	switch opts.Mode {
+	case ModeWebtoon:
+		return newStripView(pages, opts.Width, opts.Height, page)
+	case ModeSpread:
+		return newSpreadView(pages, opts, page)
+	}
+	fit := opts.Fit
+	if fit == "" {
+		fit = FitScreen
+	}
+	return &pageView{
+		pages:  pages,
+		width:  opts.Width,
+		height: opts.Height,
+		fit:    fit,
+		zoom:   1,
+		i:      clampPage(page, pages.Len()),
+	}
+}
+
+// pageView shows one page at a time. Unless it fits the screen as a whole,
+// the page is scaled by its fit mode and zoom, and the screen shows the
+// part of it at the pan offset x, y. Next and previous scroll through a
+// page that is taller than the screen before turning it.
+type pageView struct {
+	pages         *pageCache
+	width, height int
+	fit           string
+	zoom          float64
+	i             int
+	x, y          int
+
+	// scaled and box are the pixel sizes of the scaled page and of the
+	// screen at the last draw; panning is limited by them.
+	scaled, box image.Point
+}
+
+func (v *pageView) Draw() error {
+	cols, rows := viewBox(v.width, v.height)
+	if v.fit == FitScreen && v.zoom == 1 {
+		v.scaled, v.box, v.x, v.y = image.Point{}, image.Point{}, 0, 0
+		return renderPage(v.pages, v.i, cols, rows)
+	}
+
+	p := v.pages.Show(v.i, pageSize{decoded: true})
+	if p.err != nil {
+		return p.err
+	}
+	size, _ := utils.TerminalSize()
+	cellW, cellH := size.CellSize()
+
+	b := p.img.Bounds()
+	v.box = image.Pt(cols*cellW, rows*cellH)
+	s := v.scale(b.Size())
+	v.scaled = image.Pt(max(int(float64(b.Dx())*s), 1), max(int(float64(b.Dy())*s), 1))
+	v.clampPan()
+
+	shown := image.Rect(v.x, v.y, min(v.x+v.box.X, v.scaled.X), min(v.y+v.box.Y, v.scaled.Y))
+	src := image.Rect(
+		b.Min.X+int(float64(shown.Min.X)/s), b.Min.Y+int(float64(shown.Min.Y)/s),
+		b.Min.X+int(math.Ceil(float64(shown.Max.X)/s)), b.Min.Y+int(math.Ceil(float64(shown.Max.Y)/s)),
+	).Intersect(b)
+	img := imageproc.Resize(imageproc.Crop(p.img, src), shown.Dx(), shown.Dy())
+
+	// The renderers fit images to the cell box, so the box is cut down to
+	// the image to keep its scale.
+	return showImage(img, v.pages.dir, (shown.Dx()+cellW-1)/cellW, (shown.Dy()+cellH-1)/cellH)
+}
+
+// scale returns the factor a page of the given size is drawn at.
+func (v *pageView) scale(page image.Point) float64 {
+	sx := float64(v.box.X) / float64(page.X)
+	sy := float64(v.box.Y) / float64(page.Y)
+	fit := math.Min(sx, sy)
+	switch v.fit {
+	case FitWidth:
+		fit = sx
+	case FitHeight:
+		fit = sy
+	case FitOriginal:
+		fit = 1
+	}
+	return fit * v.zoom
+}
+
+func (v *pageView) clampPan() {
+	v.x = min(max(v.x, 0), max(v.scaled.X-v.box.X, 0))
+	v.y = min(max(v.y, 0), max(v.scaled.Y-v.box.Y, 0))
+}
+
+func (v *pageView) Page() int     { return v.i }
+func (v *pageView) AtStart() bool { return v.i == 0 && v.y == 0 }
+func (v *pageView) AtEnd() bool   { return v.i == v.pages.Len()-1 && v.y+v.box.Y >= v.scaled.Y }
+
+func (v *pageView) Info() string {
+	if v.fit == FitScreen && v.zoom == 1 {
+		return ""
+	}
+	return fmt.Sprintf("[%s %d%%]", v.fit, int(math.Round(v.zoom*100)))
+}
+
+func (v *pageView) Move(action string, page int) bool {
+	i, x, y, zoom := v.i, v.x, v.y, v.zoom
+	switch action {
+	case ActionNext:
+		if v.y+v.box.Y < v.scaled.Y {
+			y += v.box.Y
+		} else if i < v.pages.Len()-1 {
+			i, x, y = i+1, 0, 0
+		}
+	case ActionPrev:
+		if v.y > 0 {
+			y -= v.box.Y
+		} else if i > 0 {
+			// Continue at the bottom of the previous page.
+			i, x, y = i-1, 0, lastPage
+		}
+	case ActionFirst:
+		i, x, y = 0, 0, 0
+	case ActionLast:
+		i, x, y = v.pages.Len()-1, 0, 0
+	case actionGoTo:
+		v.i, v.x, v.y = clampPage(page, v.pages.Len()), 0, 0
+		return true
+	case ActionZoomIn, ActionZoomOut:
+		f := zoomStep
+		if action == ActionZoomOut {
+			f = 1 / zoomStep
+		}
+		zoom = min(max(v.zoom*f, minZoom), maxZoom)
+		// Keep the middle of the screen where it is.
+		f = zoom / v.zoom
+		x = int(float64(v.x+v.box.X/2)*f) - v.box.X/2
+		y = int(float64(v.y+v.box.Y/2)*f) - v.box.Y/2
+	case ActionFit:
+		v.fit = fits[(slices.Index(fits, v.fit)+1)%len(fits)]
+		v.zoom, v.x, v.y = 1, 0, 0
+		return true
+	case ActionPanUp:
+		y -= v.box.Y / 2
+	case ActionPanDown:
+		y += v.box.Y / 2
+	case ActionPanLeft:
+		x -= v.box.X / 2
+	case ActionPanRight:
+		x += v.box.X / 2
+	}
+
+	if i == v.i && zoom == v.zoom {
+		// Only the pan moved; stay within the page.
+		before := image.Pt(v.x, v.y)
+		v.x, v.y = x, y
+		v.clampPan()
+		return image.Pt(v.x, v.y) != before
+	}
+	v.i, v.x, v.y, v.zoom = i, x, y, zoom
+	return true
+}
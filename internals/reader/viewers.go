@@ -0,0 +1,117 @@
+package readerUtil
+
+import (
+	"encoding/json"
+	"fmt"
+	"manga-cli/internals/config"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+)
+
+// ViewerProfile describes how to call an external image viewer. Args may use
+// {path}, {width} and {height}; viewers that show a whole chapter at once
+// can also use {paths} (every page as its own argument) and {dir}.
+type ViewerProfile struct {
+	Command string   `json:"command"`
+	Args    []string `json:"args"`
+	Chapter bool     `json:"chapter,omitempty"`
+}
+
+var builtinProfiles = map[string]ViewerProfile{
+	"viu":    {Command: "viu", Args: []string{"-w", "{width}", "-h", "{height}", "{path}"}},
+	"chafa":  {Command: "chafa", Args: []string{"--size", "{width}x{height}", "{path}"}},
+	"timg":   {Command: "timg", Args: []string{"-g", "{width}x{height}", "{path}"}},
+	"catimg": {Command: "catimg", Args: []string{"-w", "{width}", "{path}"}},
+	"icat":   {Command: "kitten", Args: []string{"icat", "--place", "{width}x{height}@0x1", "{path}"}},
+	"feh":    {Command: "feh", Args: []string{"--scale-down", "--auto-zoom", "{paths}"}, Chapter: true},
+	"imv":    {Command: "imv", Args: []string{"{paths}"}, Chapter: true},
+	"sxiv":   {Command: "sxiv", Args: []string{"{paths}"}, Chapter: true},
+	"nsxiv":  {Command: "nsxiv", Args: []string{"{paths}"}, Chapter: true},
+}
+
+// ResolveViewer turns the viewer option into a profile. It may name a profile
+// from the "viewers" config option or a built-in one, or be a command line
+// template such as "chafa --size {width}x{height} {path}". Any other command
+// is assumed to take viu-style -w/-h flags.
+func ResolveViewer(viewer string) (ViewerProfile, error) {
+	user, err := userProfiles()
+	if err != nil {
+		return ViewerProfile{}, err
+	}
+	if p, ok := user[viewer]; ok {
+		return p, nil
+	}
+
+	if strings.Contains(viewer, "{") {
+		fields := strings.Fields(viewer)
+		p := ViewerProfile{Command: fields[0], Args: fields[1:]}
+		for _, arg := range p.Args {
+			if strings.Contains(arg, "{paths}") || strings.Contains(arg, "{dir}") {
+				p.Chapter = true
+			}
+		}
+		return p, nil
+	}
+
+	if p, ok := builtinProfiles[viewer]; ok {
+		return p, nil
+	}
+	return ViewerProfile{Command: viewer, Args: builtinProfiles["viu"].Args}, nil
+}
+
+// userProfiles reads the "viewers" option, which is either an object in
+// config.json or the same JSON given as a string to `config set`.
+func userProfiles() (map[string]ViewerProfile, error) {
+	val, err := config.GetConfigOption("viewers")
+	if err != nil || val == nil || val == "" {
+		return nil, nil
+	}
+
+	data, ok := val.(string)
+	if !ok {
+		raw, err := json.Marshal(val)
+		if err != nil {
+			return nil, err
+		}
+		data = string(raw)
+	}
+
+	var profiles map[string]ViewerProfile
+	if err := json.Unmarshal([]byte(data), &profiles); err != nil {
+		return nil, fmt.Errorf("invalid viewers config: %w", err)
+	}
+	return profiles, nil
+}
+
+func (p ViewerProfile) Available() bool {
+	_, err := exec.LookPath(p.Command)
+	return err == nil
+}
+
+// Cmd builds the viewer invocation for the given pages; path is the page
+// to show for single-page viewers.
+func (p ViewerProfile) Cmd(path string, pages []string, dir string, width, height int) *exec.Cmd {
+	r := strings.NewReplacer(
+		"{path}", path,
+		"{dir}", dir,
+		"{width}", strconv.Itoa(width),
+		"{height}", strconv.Itoa(height),
+	)
+
+	var args []string
+	for _, arg := range p.Args {
+		if arg == "{paths}" {
+			args = append(args, pages...)
+			continue
+		}
+		args = append(args, r.Replace(arg))
+	}
+
+	cmd := exec.Command(p.Command, args...)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	return cmd
+}
@@ -0,0 +1,139 @@
+package readerUtil
+
+import (
+	"image"
+	"image/draw"
+	"manga-cli/internals/utils"
+)
+
+// stripView reads a chapter as one long vertical strip, the way long strip
+// webtoons are meant to be read. Pages are scaled to the width of the box
+// and stacked, and every move scrolls by the height of the box, so the end
+// of one page and the start of the next can share the screen.
+type stripView struct {
+	pages         *pageCache
+	width, height int
+	viewport      int
+
+	// page is the page at the top of the screen and offset how many pixels
+	// of it are scrolled past.
+	page, offset int
+}
+
+func newStripView(pages *pageCache, width, height, page int) *stripView {
+	size, _ := utils.TerminalSize()
+	_, cellH := size.CellSize()
+
+	width, height = viewBox(width, height)
+	v := &stripView{pages: pages, width: width, height: height, viewport: max(height*cellH, 1)}
+	if page == lastPage {
+		v.toEnd()
+	} else {
+		v.page = clampPage(page, pages.Len())
+	}
+	return v
+}
+
+func (v *stripView) image(i int) (image.Image, error) {
+	p := v.pages.Show(i, pageSize{width: v.width, decoded: true})
+	return p.img, p.err
+}
+
+// pageHeight returns the scaled height of page i in pixels. A page that
+// cannot be loaded counts as one screen.
+func (v *stripView) pageHeight(i int) int {
+	img, err := v.image(i)
+	if err != nil {
+		return v.viewport
+	}
+	return img.Bounds().Dy()
+}
+
+// Draw stitches the visible parts of the pages under each other and shows
+// them as one image.
+func (v *stripView) Draw() error {
+	first, err := v.image(v.page)
+	if err != nil {
+		return err
+	}
+	canvas := image.NewRGBA(image.Rect(0, 0, first.Bounds().Dx(), v.viewport))
+
+	y, offset := 0, v.offset
+	for i := v.page; i < v.pages.Len() && y < v.viewport; i++ {
+		img, err := v.image(i)
+		if err != nil {
+			return err
+		}
+		b := img.Bounds()
+		h := min(b.Dy()-offset, v.viewport-y)
+		draw.Draw(canvas, image.Rect(0, y, b.Dx(), y+h), img, image.Pt(b.Min.X, b.Min.Y+offset), draw.Src)
+		y, offset = y+h, 0
+	}
+	strip := canvas.SubImage(image.Rect(0, 0, canvas.Bounds().Dx(), y))
+	return showImage(strip, v.pages.dir, v.width, v.height)
+}
+
+func (v *stripView) Page() int     { return v.page }
+func (v *stripView) AtStart() bool { return v.page == 0 && v.offset == 0 }
+func (v *stripView) AtEnd() bool   { return v.remaining() <= v.viewport }
+func (v *stripView) Info() string  { return "" }
+
+func (v *stripView) Move(action string, page int) bool {
+	before := [2]int{v.page, v.offset}
+	switch action {
+	case ActionNext:
+		if !v.AtEnd() {
+			v.scroll(v.viewport)
+		}
+	case ActionPrev:
+		v.scroll(-v.viewport)
+	case ActionFirst:
+		v.page, v.offset = 0, 0
+	case ActionLast:
+		v.toEnd()
+	case actionGoTo:
+		v.page, v.offset = clampPage(page, v.pages.Len()), 0
+		return true
+	}
+	return before != [2]int{v.page, v.offset}
+}
+
+// scroll moves the strip by d pixels, stopping at its top and where its
+// bottom reaches the bottom of the screen.
+func (v *stripView) scroll(d int) {
+	v.offset += d
+	for v.offset < 0 && v.page > 0 {
+		v.page--
+		v.offset += v.pageHeight(v.page)
+	}
+	v.offset = max(v.offset, 0)
+
+	for v.page < v.pages.Len()-1 && v.offset >= v.pageHeight(v.page) {
+		v.offset -= v.pageHeight(v.page)
+		v.page++
+	}
+	if d > 0 && v.remaining() < v.viewport {
+		v.toEnd()
+	}
+}
+
+// toEnd scrolls to the bottom of the strip.
+func (v *stripView) toEnd() {
+	v.page = v.pages.Len() - 1
+	v.offset = v.pageHeight(v.page) - v.viewport
+	for v.offset < 0 && v.page > 0 {
+		v.page--
+		v.offset += v.pageHeight(v.page)
+	}
+	v.offset = max(v.offset, 0)
+}
+
+// remaining returns how many pixels of the strip are left from the top of
+// the screen, counting no further than one screen past it.
+func (v *stripView) remaining() int {
+	total := -v.offset
+	for i := v.page; i < v.pages.Len() && total <= v.viewport; i++ {
+		total += v.pageHeight(i)
+	}
+	return total
+}
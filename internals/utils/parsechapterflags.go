@@ -35,3 +35,42 @@ func ParseChapterFlags(chapter int, chaptersStr string, from, to int) ([]int, er
 
 	return result, nil
 }
+
+type ChapterRange struct {
+	From float64
+	To   float64
+}
+
+func (r ChapterRange) Contains(n float64) bool {
+	return n >= r.From && n <= r.To
+}
+
+// ParseChapterRanges reads specs like "1-50,52,60.5" into inclusive ranges.
+func ParseChapterRanges(spec string) ([]ChapterRange, error) {
+	var ranges []ChapterRange
+	for _, part := range strings.Split(spec, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+
+		lo, hi, isRange := strings.Cut(part, "-")
+		from, err := strconv.ParseFloat(strings.TrimSpace(lo), 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid chapter number: %s", part)
+		}
+		to := from
+		if isRange {
+			to, err = strconv.ParseFloat(strings.TrimSpace(hi), 64)
+			if err != nil || to < from {
+				return nil, fmt.Errorf("invalid chapter range: %s", part)
+			}
+		}
+		ranges = append(ranges, ChapterRange{From: from, To: to})
+	}
+
+	if len(ranges) == 0 {
+		return nil, errors.New("no chapters given")
+	}
+	return ranges, nil
+}
@@ -0,0 +1,17 @@
+package utils
+
+// TermSize is a terminal size in character cells and pixels. Width and
+// Height are zero when the terminal does not report them.
+type TermSize struct {
+	Cols, Rows    int
+	Width, Height int
+}
+
+// CellSize returns the pixel size of one character cell, assuming a common
+// 10x20 cell when the terminal does not report pixels.
+func (t TermSize) CellSize() (int, int) {
+	if t.Cols == 0 || t.Rows == 0 || t.Width == 0 || t.Height == 0 {
+		return 10, 20
+	}
+	return t.Width / t.Cols, t.Height / t.Rows
+}
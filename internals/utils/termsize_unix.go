@@ -0,0 +1,24 @@
+//go:build !windows
+
+package utils
+
+import (
+	"os"
+	"syscall"
+	"unsafe"
+)
+
+type winsize struct {
+	Rows, Cols, XPixel, YPixel uint16
+}
+
+// TerminalSize reports the size of the terminal on stdout in cells and, when
+// the terminal provides it, in pixels.
+func TerminalSize() (TermSize, error) {
+	var ws winsize
+	_, _, errno := syscall.Syscall(syscall.SYS_IOCTL, os.Stdout.Fd(), uintptr(syscall.TIOCGWINSZ), uintptr(unsafe.Pointer(&ws)))
+	if errno != 0 {
+		return TermSize{}, errno
+	}
+	return TermSize{Cols: int(ws.Cols), Rows: int(ws.Rows), Width: int(ws.XPixel), Height: int(ws.YPixel)}, nil
+}
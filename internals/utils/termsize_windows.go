@@ -0,0 +1,29 @@
+//go:build windows
+
+package utils
+
+import (
+	"os"
+	"syscall"
+	"unsafe"
+)
+
+var getConsoleScreenBufferInfo = syscall.NewLazyDLL("kernel32.dll").NewProc("GetConsoleScreenBufferInfo")
+
+type consoleScreenBufferInfo struct {
+	Size, CursorPosition     [2]int16
+	Attributes               uint16
+	Left, Top, Right, Bottom int16
+	MaximumWindowSize        [2]int16
+}
+
+// TerminalSize reports the size of the console in cells. Windows consoles do
+// not expose their pixel size.
+func TerminalSize() (TermSize, error) {
+	var info consoleScreenBufferInfo
+	ret, _, err := getConsoleScreenBufferInfo.Call(os.Stdout.Fd(), uintptr(unsafe.Pointer(&info)))
+	if ret == 0 {
+		return TermSize{}, err
+	}
+	return TermSize{Cols: int(info.Right-info.Left) + 1, Rows: int(info.Bottom-info.Top) + 1}, nil
+}
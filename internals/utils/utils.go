@@ -12,7 +12,6 @@ import (
 func GetOrCreateMangaCliDir() (string, error) {
 	configPath, err := config.GetConfigOption("path")
 	var basePath string
-	print(configPath)
 
 	if err != nil || configPath == nil || configPath == "" {
 		homeDir, err := os.UserHomeDir()
@@ -39,14 +38,27 @@ func GetPathByTitleAndChapter(title string, chapter int) (string, error) {
 		return "", err
 	}
 
-	chapterStr := strconv.Itoa(chapter)
-	path := filepath.Join(mangaCliDir, title, chapterStr)
-
-	if _, err := os.Stat(path); os.IsNotExist(err) {
+	path, err := FindChapterPath(filepath.Join(mangaCliDir, title), strconv.Itoa(chapter))
+	if os.IsNotExist(err) {
 		return "", fmt.Errorf("manga doesn't exist; download it using the 'download' command: %w", err)
 	}
+	return path, err
+}
+
+// FindChapterPath looks for a chapter folder inside mangaDir and falls back
+// to an archive named after the chapter, such as 12.cbz.
+func FindChapterPath(mangaDir, chapter string) (string, error) {
+	path := filepath.Join(mangaDir, chapter)
+	if _, err := os.Stat(path); err == nil {
+		return path, nil
+	}
 
-	return path, nil
+	for _, ext := range []string{".cbz", ".zip", ".cbr", ".rar"} {
+		if _, err := os.Stat(path + ext); err == nil {
+			return path + ext, nil
+		}
+	}
+	return "", &os.PathError{Op: "stat", Path: path, Err: os.ErrNotExist}
 }
 
 